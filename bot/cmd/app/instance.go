@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"telegramBot/bot/internal/analytics"
+	contentclient "telegramBot/bot/internal/client"
+	"telegramBot/bot/internal/config"
+	"telegramBot/bot/internal/dispatcher"
+	"telegramBot/bot/internal/handlers"
+	"telegramBot/bot/internal/menu"
+	"telegramBot/bot/internal/middleware"
+	"telegramBot/bot/internal/nlpclient"
+	"telegramBot/bot/internal/offset"
+	"telegramBot/bot/internal/outbox"
+	"telegramBot/bot/internal/privacy"
+	"telegramBot/bot/internal/profile"
+	"telegramBot/bot/internal/resolver"
+	"telegramBot/bot/internal/session"
+	"telegramBot/bot/internal/webhook"
+)
+
+// instance — один бот из конфигурации со своим состоянием, очередями и offset.
+type instance struct {
+	cfg config.Config
+	bc  config.Bot
+
+	api      *tgbotapi.BotAPI
+	out      *outbox.Outbox
+	h        *handlers.Bot
+	tracker  *offset.Tracker
+	d        *dispatcher.Dispatcher
+	sessions session.Sweeper
+	pool     *pgxpool.Pool
+
+	updates  <-chan tgbotapi.Update
+	polling  bool
+	quit     chan struct{}
+	loopDone chan struct{}
+}
+
+func newInstance(cfg config.Config, bc config.Bot, pool *pgxpool.Pool, logger *slog.Logger, maintenance func() bool) (*instance, error) {
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(bc.Token, cfg.APIEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	tracker, err := offset.NewTracker(offset.FileStore{Path: bc.OffsetFile})
+	if err != nil {
+		return nil, err
+	}
+
+	content := contentclient.New(cfg.APIBase)
+	content.Tenant = bc.Tenant
+	nlp := nlpclient.New(cfg.NLPBase)
+	nlp.Prompts = bc.Prompts
+
+	out := outbox.New(api, cfg.QueueSize)
+	h := handlers.New(out, content, nlp)
+	h.DefaultLang = bc.DefaultLang
+	h.GrantThreshold = bc.GrantThreshold
+	h.MenuRoot = bc.MenuRoot
+	if pool != nil {
+		h.Menu = menu.NewRepo(pool, bc.Tenant)
+		h.Analytics = analytics.NewPostgres(pool, bc.Name)
+	}
+	h.Privacy.Bot = bc.Name
+	h.Privacy.Audit = privacy.LogAudit{Logger: logger}
+	var sessions session.Sweeper
+	if pool != nil {
+		h.Privacy.Audit = privacy.PostgresAudit{DB: pool}
+		pg := session.NewPostgres(pool, bc.Name)
+		pg.TTL, pg.MaxSessions = cfg.SessionTTL, cfg.SessionMax
+		h.Sessions, sessions = pg, pg
+		h.Profiles = profile.NewPostgres(pool, bc.Name)
+	} else {
+		mem := session.NewMemory(cfg.SessionTTL, cfg.SessionMax)
+		h.Sessions, sessions = mem, mem
+		// без базы профили лежат рядом с offset, чтобы пережить перезапуск
+		profiles, err := profile.NewFile(filepath.Join(filepath.Dir(bc.OffsetFile), "profiles-"+bc.Name+".json"))
+		if err != nil {
+			return nil, err
+		}
+		h.Profiles = profiles
+	}
+
+	api.Request(tgbotapi.NewSetMyCommands(h.Router.Commands()...))
+
+	handle := middleware.Chain(h.HandleMessage,
+		acknowledge(tracker),
+		middleware.Recover(logger),
+		middleware.Logging(logger),
+		middleware.Timing(logger, cfg.SlowHandler),
+		middleware.Maintenance(maintenance, h.ReplyMaintenance),
+		middleware.RateLimit(cfg.RateLimitPerMin, cfg.RateLimitBurst, h.ReplyRateLimited),
+	)
+	d := dispatcher.New(cfg.Workers, cfg.QueueSize, handle)
+	d.Start()
+
+	return &instance{
+		cfg: cfg, bc: bc,
+		api: api, out: out, h: h, tracker: tracker, d: d,
+		sessions: sessions,
+		pool:     pool,
+		quit:     make(chan struct{}),
+		loopDone: make(chan struct{}),
+	}, nil
+}
+
+// listen подключает источник апдейтов: long polling или путь на общем
+// webhook-сервере (mux != nil).
+func (in *instance) listen(mux *http.ServeMux) error {
+	if mux == nil {
+		if _, err := in.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			log.Printf("bot %s: deleteWebhook: %v", in.bc.Name, err)
+		}
+		u := tgbotapi.NewUpdate(in.tracker.Offset())
+		u.Timeout = 60
+		in.updates = in.api.GetUpdatesChan(u)
+		in.polling = true
+		log.Printf("Bot @%s (%s) started in polling mode", in.api.Self.UserName, in.bc.Name)
+		return nil
+	}
+
+	path := in.cfg.WebhookPath
+	if in.bc.Name != config.DefaultBot {
+		path = strings.TrimRight(path, "/") + "/" + in.bc.Name
+	}
+	rc := webhook.New(in.cfg.WebhookSecret, in.cfg.QueueSize)
+	mux.Handle(path, rc)
+	in.updates = rc.Updates
+
+	if in.cfg.WebhookURL != "" {
+		url := strings.TrimRight(in.cfg.WebhookURL, "/")
+		if in.bc.Name != config.DefaultBot {
+			url += "/" + in.bc.Name
+		}
+		if err := webhook.Register(in.api, url, in.cfg.WebhookSecret); err != nil {
+			return err
+		}
+	} else {
+		log.Printf("bot %s: WEBHOOK_URL пустой — setWebhook пропущен, ждём апдейты на локальном эндпоинте", in.bc.Name)
+	}
+	log.Printf("Bot @%s (%s) started in webhook mode on %s", in.api.Self.UserName, in.bc.Name, path)
+	return nil
+}
+
+// offsetFlush — как часто сохранять offset. После падения (не штатной
+// остановки) повторно придут апдейты, обработанные за последний интервал.
+const offsetFlush = time.Second
+
+func (in *instance) saveOffsets(ctx context.Context) {
+	in.tracker.Run(ctx, offsetFlush)
+}
+
+func (in *instance) janitor(ctx context.Context) {
+	session.RunJanitor(ctx, in.sessions, in.cfg.SessionSweep, session.NewMetrics(in.bc.Name))
+}
+
+// synonyms перечитывает синонимы резолвера из базы, пока не отменён ctx.
+func (in *instance) synonyms(ctx context.Context) {
+	if in.pool == nil {
+		return
+	}
+	t := time.NewTicker(in.cfg.SynonymsRefresh)
+	defer t.Stop()
+	for {
+		lctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		syns, err := resolver.LoadSynonyms(lctx, in.pool, in.bc.Tenant)
+		cancel()
+		if err != nil {
+			log.Printf("bot %s: synonyms: %v", in.bc.Name, err)
+		} else {
+			in.h.Resolver.SetSynonyms(syns)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (in *instance) run() {
+	defer close(in.loopDone)
+	for {
+		select {
+		case <-in.quit:
+			return
+		case upd, ok := <-in.updates:
+			if !ok {
+				return
+			}
+			in.accept(upd)
+		}
+	}
+}
+
+// drain раздаёт воркерам апдейты, которые уже получены, но ещё не разосланы.
+func (in *instance) drain() {
+	for {
+		select {
+		case upd, ok := <-in.updates:
+			if !ok {
+				return
+			}
+			in.accept(upd)
+		default:
+			return
+		}
+	}
+}
+
+func (in *instance) accept(upd tgbotapi.Update) {
+	if in.tracker.Begin(upd.UpdateID) {
+		in.d.Dispatch(upd)
+	}
+}
+
+// stop перестаёт принимать апдейты, дожидается хендлеров и отправки
+// исходящих сообщений, но не дольше, чем живёт ctx.
+func (in *instance) stop(ctx context.Context) {
+	if in.polling {
+		in.api.StopReceivingUpdates()
+	}
+	close(in.quit)
+	drained := make(chan struct{})
+	go func() {
+		<-in.loopDone
+		in.drain()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		// очереди полны; Shutdown ниже отпустит ждущий Dispatch, а
+		// непринятые апдейты не подтверждены и придут снова
+	}
+
+	if err := in.d.Shutdown(ctx); err != nil {
+		log.Printf("bot %s: handlers did not finish in time: %v", in.bc.Name, err)
+	}
+	if err := in.out.Close(ctx); err != nil {
+		log.Printf("bot %s: outgoing messages not flushed: %v", in.bc.Name, err)
+	}
+	if err := in.tracker.Flush(); err != nil {
+		log.Printf("bot %s: offset save: %v", in.bc.Name, err)
+	}
+}
@@ -2,56 +2,179 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 
-	"telegramBot/bot/internal/client"
 	"telegramBot/bot/internal/config"
-	"telegramBot/bot/internal/handlers"
-	"telegramBot/bot/internal/nlpclient"
+	"telegramBot/bot/internal/middleware"
+	"telegramBot/bot/internal/offset"
 )
 
 func main() {
 	cfg := config.FromEnv()
-	if cfg.Token == "" || cfg.APIBase == "" || cfg.NLPBase == "" {
-		log.Fatal("TELEGRAM_TOKEN, CONTENT_API_URL или NLP_API_URL пустые")
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
 	}
-
-	b, err := tgbotapi.NewBotAPI(cfg.Token)
+	bots, err := cfg.Bots()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	h := &handlers.Bot{
-		API:   b,
-		APICl: contentclient.New(cfg.APIBase),
-		NLP:   nlpclient.New(cfg.NLPBase),
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var maintenance atomic.Bool
+	maintenance.Store(cfg.Maintenance)
+	go toggleMaintenance(ctx, &maintenance)
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	// Без DATABASE_URL сессии живут в памяти и теряются при перезапуске.
+	var pool *pgxpool.Pool
+	if cfg.DatabaseURL != "" {
+		pool, err = openDB(cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("database: %v", err)
+		}
+		defer pool.Close()
+	} else {
+		log.Println("DATABASE_URL пустой — сессии хранятся в памяти")
+		// у каждого бота своя память, поэтому общий лимит делится поровну
+		if cfg.SessionMax > 0 {
+			cfg.SessionMax = max(1, cfg.SessionMax/len(bots))
+		}
 	}
 
-	b.Request(tgbotapi.NewSetMyCommands(
-		tgbotapi.BotCommand{Command: "start", Description: "Запустить бота"},
-		tgbotapi.BotCommand{Command: "help", Description: "Помощь"},
-	))
+	var mux *http.ServeMux
+	if cfg.Mode == "webhook" {
+		mux = http.NewServeMux()
+		mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("ok"))
+		})
+	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	var insts []*instance
+	for _, bc := range bots {
+		in, err := newInstance(cfg, bc, pool, logger.With("bot", bc.Name), maintenance.Load)
+		if err != nil {
+			log.Fatalf("bot %s: %v", bc.Name, err)
+		}
+		if err := in.listen(mux); err != nil {
+			log.Fatalf("bot %s: %v", bc.Name, err)
+		}
+		insts = append(insts, in)
+	}
+
+	var srv *http.Server
+	if mux != nil {
+		srv = &http.Server{Addr: cfg.WebhookListen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("webhook server: %v", err)
+			}
+		}()
+		log.Printf("webhook server listening on %s", cfg.WebhookListen)
+	}
+
+	if cfg.MetricsAddr != "" {
+		go func() {
+			m := http.NewServeMux()
+			m.Handle("/debug/vars", expvar.Handler())
+			log.Printf("metrics on %s/debug/vars", cfg.MetricsAddr)
+			if err := http.ListenAndServe(cfg.MetricsAddr, m); err != nil {
+				log.Printf("metrics server: %v", err)
+			}
+		}()
+	}
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := b.GetUpdatesChan(u)
+	for _, in := range insts {
+		go in.run()
+		go in.janitor(ctx)
+		go in.saveOffsets(ctx)
+		go in.synonyms(ctx)
+	}
+
+	<-ctx.Done()
+	log.Printf("Shutting down, draining handlers (timeout %s)...", cfg.ShutdownTimeout)
+	sctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Сначала закрываем webhook-сервер: запросы, которые он уже принял,
+	// ещё успевают попасть в очереди работающих инстансов.
+	if srv != nil {
+		if err := srv.Shutdown(sctx); err != nil {
+			log.Printf("webhook server shutdown: %v", err)
+		}
+	}
+	var wg sync.WaitGroup
+	for _, in := range insts {
+		wg.Add(1)
+		go func(in *instance) {
+			defer wg.Done()
+			in.stop(sctx)
+		}(in)
+	}
+	wg.Wait()
+	log.Println("Bye")
+}
+
+func openDB(url string) (*pgxpool.Pool, error) {
+	pcfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	pcfg.MaxConns = 10
+	pcfg.MaxConnLifetime = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pool, err := pgxpool.NewWithConfig(ctx, pcfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// acknowledge отмечает апдейт обработанным, даже если хендлер упал с паникой:
+// повторная доставка той же паники ничем не поможет.
+func acknowledge(t *offset.Tracker) middleware.Middleware {
+	return func(next middleware.Handler) middleware.Handler {
+		return func(upd tgbotapi.Update) {
+			defer t.Done(upd.UpdateID)
+			next(upd)
+		}
+	}
+}
 
-	log.Printf("Bot @%s started", b.Self.UserName)
+// toggleMaintenance переключает режим обслуживания по SIGUSR1 без перезапуска.
+func toggleMaintenance(ctx context.Context, flag *atomic.Bool) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1)
+	defer signal.Stop(ch)
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Shutting down...")
 			return
-		case upd := <-updates:
-			h.HandleMessage(upd)
+		case <-ch:
+			log.Printf("maintenance mode: %v", !flag.Load())
+			flag.Store(!flag.Load())
 		}
 	}
 }
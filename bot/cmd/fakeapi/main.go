@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBot/bot/internal/fakeapi"
+)
+
+// Локальный Telegram без сети: запустите его, затем бота с
+// TELEGRAM_API_ENDPOINT=http://localhost:8081/bot%s/%s и любым TELEGRAM_TOKEN.
+//
+//	curl -d '{"chat_id":42,"text":"/start"}' localhost:8081/fake/message
+//	curl localhost:8081/fake/sent
+//
+// Нумерация update_id при каждом запуске начинается с 1. Если бот уже
+// работал с фейком, перед перезапуском фейка удалите OFFSET_FILE
+// (data/offset*.json) и перезапустите бота: иначе он сочтёт новые апдейты
+// повторами уже обработанных и будет ждать update_id больше сохранённого.
+func main() {
+	addr := flag.String("addr", ":8081", "listen address")
+	flag.Parse()
+
+	fake := fakeapi.New()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /fake/message", func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			ChatID int64  `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.ChatID == 0 {
+			http.Error(w, "expected {chat_id, text}", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, fake.PushText(in.ChatID, in.Text))
+	})
+	mux.HandleFunc("POST /fake/update", func(w http.ResponseWriter, r *http.Request) {
+		var upd tgbotapi.Update
+		if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+			http.Error(w, "bad update", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, fake.Push(upd))
+	})
+	mux.HandleFunc("GET /fake/sent", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, fake.Sent())
+	})
+	mux.Handle("/", fake)
+
+	log.Printf("fake Telegram Bot API listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
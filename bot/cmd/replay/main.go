@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	contentclient "telegramBot/bot/internal/client"
+	"telegramBot/bot/internal/handlers"
+	"telegramBot/bot/internal/nlpclient"
+	"telegramBot/bot/internal/sender"
+)
+
+// Conversation — одна записанная переписка: строка во входном JSONL.
+type Conversation struct {
+	Name     string `json:"name"`
+	ChatID   int64  `json:"chat_id,omitempty"`
+	LangCode string `json:"lang_code,omitempty"`
+	Steps    []Step `json:"steps"`
+}
+
+// Step — одно действие пользователя и ожидаемые ответы бота.
+// Send — текст сообщения, Callback — нажатие inline-кнопки с этими данными.
+type Step struct {
+	Send     string     `json:"send,omitempty"`
+	Callback string     `json:"callback,omitempty"`
+	NLP      *NLPScript `json:"nlp,omitempty"`
+	Expect   []string   `json:"expect"`
+}
+
+// Реплей прогоняет переписки через handlers.Bot с заглушками content-api
+// и nlp-api и сравнивает ответы бота с ожидаемыми.
+//
+//	go run ./bot/cmd/replay
+//	go run ./bot/cmd/replay -update   # перезаписать ожидания фактическими ответами
+func main() {
+	in := flag.String("in", "bot/testdata/replay/requests.jsonl", "recorded conversations (JSONL)")
+	content := flag.String("content", "bot/testdata/replay/content.json", "content fixtures for the content-api stub")
+	update := flag.Bool("update", false, "rewrite expectations with the actual replies")
+	only := flag.String("run", "", "replay only conversations whose name contains this string")
+	flag.Parse()
+
+	convs, err := load(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cs, err := contentStub(*content)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cs.Close()
+	nlp := &nlpStub{}
+	ns := httptest.NewServer(nlp)
+	defer ns.Close()
+
+	failed := 0
+	for i := range convs {
+		c := &convs[i]
+		if *only != "" && !strings.Contains(c.Name, *only) {
+			continue
+		}
+		if c.ChatID == 0 {
+			c.ChatID = int64(1000 + i)
+		}
+		diffs := replay(c, cs.URL, ns.URL, nlp, *update)
+		if len(diffs) == 0 {
+			fmt.Printf("ok    %s\n", c.Name)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL  %s\n", c.Name)
+		for _, d := range diffs {
+			fmt.Println(d)
+		}
+	}
+
+	if *update {
+		if err := save(*in, convs); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("expectations written to %s\n", *in)
+		return
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d conversations differ\n", failed, len(convs))
+		os.Exit(1)
+	}
+}
+
+func replay(c *Conversation, contentURL, nlpURL string, nlp *nlpStub, update bool) []string {
+	rec := sender.NewRecorder()
+	b := handlers.New(rec, contentclient.New(contentURL), nlpclient.New(nlpURL))
+	b.Privacy.Audit = nil // аудит /mydata и /forget реплею не интересен
+
+	var diffs []string
+	for i, st := range c.Steps {
+		nlp.set(st.NLP)
+		rec.Reset()
+		b.HandleMessage(makeUpdate(c, i, st))
+		got := rec.Texts()
+
+		if update {
+			c.Steps[i].Expect = got
+			continue
+		}
+		if d := diff(st.Expect, got); d != "" {
+			diffs = append(diffs, fmt.Sprintf("  step %d %s\n%s", i+1, describe(st), d))
+		}
+	}
+	return diffs
+}
+
+func makeUpdate(c *Conversation, i int, st Step) tgbotapi.Update {
+	from := &tgbotapi.User{ID: c.ChatID, FirstName: "replay", LanguageCode: c.LangCode}
+	chat := &tgbotapi.Chat{ID: c.ChatID, Type: "private"}
+	if st.Callback != "" {
+		return tgbotapi.Update{UpdateID: i + 1, CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      fmt.Sprintf("cb%d", i+1),
+			From:    from,
+			Message: &tgbotapi.Message{MessageID: 1, Chat: chat},
+			Data:    st.Callback,
+		}}
+	}
+	msg := &tgbotapi.Message{MessageID: i + 1, From: from, Chat: chat, Text: st.Send}
+	if strings.HasPrefix(st.Send, "/") {
+		n := strings.IndexAny(st.Send, " \n")
+		if n < 0 {
+			n = len(st.Send)
+		}
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Length: n}}
+	}
+	return tgbotapi.Update{UpdateID: i + 1, Message: msg}
+}
+
+func describe(st Step) string {
+	if st.Callback != "" {
+		return "[callback " + st.Callback + "]"
+	}
+	return fmt.Sprintf("%q", st.Send)
+}
+
+// diff печатает ожидаемые строки с "-" и фактические с "+",
+// пропуская общее начало и конец.
+func diff(want, got []string) string {
+	p := 0
+	for p < len(want) && p < len(got) && want[p] == got[p] {
+		p++
+	}
+	if p == len(want) && p == len(got) {
+		return ""
+	}
+	s := 0
+	for s < len(want)-p && s < len(got)-p && want[len(want)-1-s] == got[len(got)-1-s] {
+		s++
+	}
+	var sb strings.Builder
+	for _, w := range want[p : len(want)-s] {
+		sb.WriteString("    - " + quoteLines(w) + "\n")
+	}
+	for _, g := range got[p : len(got)-s] {
+		sb.WriteString("    + " + quoteLines(g) + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func quoteLines(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n      ")
+}
+
+// load читает JSONL и пропускает строки без steps, чтобы в одном файле
+// можно было держать и другие записи трафика.
+func load(path string) ([]Conversation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Conversation
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 1<<20), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var c Conversation
+		if err := json.Unmarshal(line, &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		if len(c.Steps) == 0 {
+			continue
+		}
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("line %d", n)
+		}
+		out = append(out, c)
+	}
+	return out, sc.Err()
+}
+
+func save(path string, convs []Conversation) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, c := range convs {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
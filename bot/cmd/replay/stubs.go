@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+)
+
+// NLPScript — что заглушка nlp-api ответит на текущем шаге.
+// Без скрипта /ask ничего не распознаёт, а /chat_plus отвечает "llm: <текст>".
+type NLPScript struct {
+	Slug       string  `json:"slug,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+	Mini       *string `json:"mini,omitempty"`
+	LLM        string  `json:"llm,omitempty"`
+	Down       bool    `json:"down,omitempty"`
+}
+
+type nlpStub struct {
+	mu     sync.Mutex
+	script *NLPScript
+}
+
+func (s *nlpStub) set(sc *NLPScript) {
+	s.mu.Lock()
+	s.script = sc
+	s.mu.Unlock()
+}
+
+func (s *nlpStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	sc := s.script
+	s.mu.Unlock()
+	if sc == nil {
+		sc = &NLPScript{}
+	}
+	if sc.Down {
+		http.Error(w, "nlp is down", http.StatusServiceUnavailable)
+		return
+	}
+	var in struct {
+		Text string `json:"text"`
+	}
+	json.NewDecoder(r.Body).Decode(&in)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/ask":
+		json.NewEncoder(w).Encode(map[string]any{"slug": sc.Slug, "confidence": sc.Confidence})
+	case "/chat_plus":
+		llm := sc.LLM
+		if llm == "" {
+			llm = "llm: " + in.Text
+		}
+		json.NewEncoder(w).Encode(map[string]any{"mini_answer": sc.Mini, "llm_answer": llm})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// contentStub отдаёт контент из JSON-файла вида {"slug/lang": {"title":..,"body":..}}
+// с тем же откатом на ru, что и настоящий content-api.
+func contentStub(path string) (*httptest.Server, error) {
+	data := map[string]json.RawMessage{}
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, err
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/content" {
+			http.NotFound(w, r)
+			return
+		}
+		slug, lang := r.URL.Query().Get("slug"), r.URL.Query().Get("lang")
+		c, ok := data[slug+"/"+lang]
+		if !ok && lang != "ru" {
+			c, ok = data[slug+"/ru"]
+		}
+		if !ok || strings.TrimSpace(slug) == "" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(c)
+	})), nil
+}
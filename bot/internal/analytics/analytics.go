@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Event — одно событие воронки, например переход по ссылке с плаката.
+// Текст сообщений сюда не попадает.
+type Event struct {
+	UserID  int64     `json:"user_id"`
+	Kind    string    `json:"kind"`
+	Source  string    `json:"source,omitempty"`
+	Payload string    `json:"payload,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+const KindStart = "start"
+
+type Store interface {
+	Record(ctx context.Context, e Event) error
+	// Events — все события пользователя для /mydata.
+	Events(ctx context.Context, userID int64) ([]Event, error)
+	Erase(ctx context.Context, userID int64) error
+}
+
+// Memory держит последние Limit событий в памяти — когда базы нет.
+type Memory struct {
+	Limit int
+
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewMemory(limit int) *Memory { return &Memory{Limit: limit} }
+
+func (m *Memory) Record(_ context.Context, e Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+	if m.Limit > 0 && len(m.events) > m.Limit {
+		m.events = append([]Event(nil), m.events[len(m.events)-m.Limit:]...)
+	}
+	return nil
+}
+
+func (m *Memory) Events(_ context.Context, userID int64) ([]Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Event
+	for _, e := range m.events {
+		if e.UserID == userID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *Memory) Erase(_ context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.events[:0]
+	for _, e := range m.events {
+		if e.UserID != userID {
+			kept = append(kept, e)
+		}
+	}
+	m.events = kept
+	return nil
+}
+
+// Postgres пишет события в таблицу bot_events (см. init.sql).
+type Postgres struct {
+	DB  *pgxpool.Pool
+	Bot string
+}
+
+func NewPostgres(db *pgxpool.Pool, bot string) *Postgres {
+	return &Postgres{DB: db, Bot: bot}
+}
+
+func (p *Postgres) Record(ctx context.Context, e Event) error {
+	_, err := p.DB.Exec(ctx,
+		`insert into bot_events (bot, user_id, kind, source, payload, created_at) values ($1, $2, $3, $4, $5, $6)`,
+		p.Bot, e.UserID, e.Kind, e.Source, e.Payload, e.At)
+	return err
+}
+
+func (p *Postgres) Events(ctx context.Context, userID int64) ([]Event, error) {
+	rows, err := p.DB.Query(ctx,
+		`select user_id, kind, source, payload, created_at from bot_events where bot=$1 and user_id=$2 order by created_at`,
+		p.Bot, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.UserID, &e.Kind, &e.Source, &e.Payload, &e.At); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (p *Postgres) Erase(ctx context.Context, userID int64) error {
+	_, err := p.DB.Exec(ctx, `delete from bot_events where bot=$1 and user_id=$2`, p.Bot, userID)
+	return err
+}
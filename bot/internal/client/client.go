@@ -9,8 +9,9 @@ import (
 )
 
 type Client struct {
-	Base string
-	HC   *http.Client
+	Base   string
+	Tenant string
+	HC     *http.Client
 }
 
 type Content struct {
@@ -28,6 +29,9 @@ func New(base string) *Client {
 func (c *Client) Get(slug, lang string) (Content, error) {
 	var out Content
 	u := fmt.Sprintf("%s/content?slug=%s&lang=%s", c.Base, url.QueryEscape(slug), url.QueryEscape(lang))
+	if c.Tenant != "" {
+		u += "&tenant=" + url.QueryEscape(c.Tenant)
+	}
 	resp, err := c.HC.Get(u)
 	if err != nil {
 		return out, err
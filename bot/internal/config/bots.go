@@ -0,0 +1,103 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Bot — один Telegram-бот в процессе: свой токен, свой tenant в content-api,
+// язык по умолчанию и системные промпты для NLP.
+type Bot struct {
+	Name        string            `json:"name"`
+	Token       string            `json:"token,omitempty"`
+	TokenEnv    string            `json:"token_env,omitempty"`
+	Tenant      string            `json:"tenant,omitempty"`
+	DefaultLang string            `json:"default_lang,omitempty"`
+	Prompts     map[string]string `json:"prompts,omitempty"`
+	OffsetFile  string            `json:"offset_file,omitempty"`
+	// GrantThreshold — порог ЕНТ для конкурса на грант; 0 — GRANT_THRESHOLD.
+	GrantThreshold int `json:"grant_threshold,omitempty"`
+	// MenuRoot — код корневого узла меню бота; пусто — MENU_ROOT.
+	MenuRoot string `json:"menu_root,omitempty"`
+}
+
+const (
+	DefaultBot = "default"
+	// DefaultTenant — tenant в content-api и таблицах меню, если у бота свой не задан.
+	DefaultTenant = "default"
+)
+
+var reBotName = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// Bots возвращает список ботов из BOTS_FILE, а без него — единственного бота
+// из TELEGRAM_TOKEN, как раньше.
+//
+//	[{"name":"wkatu","token_env":"WKATU_TOKEN","tenant":"wkatu"},
+//	 {"name":"college","token_env":"COLLEGE_TOKEN","tenant":"college","default_lang":"kz",
+//	  "prompts":{"ru":"Ты помощник приёмной комиссии колледжа..."}}]
+func (c Config) Bots() ([]Bot, error) {
+	if c.BotsFile == "" {
+		if c.Token == "" {
+			return nil, fmt.Errorf("TELEGRAM_TOKEN пустой")
+		}
+		return []Bot{{Name: DefaultBot, Token: c.Token, Tenant: DefaultTenant, DefaultLang: "ru",
+			OffsetFile: c.OffsetFile, GrantThreshold: c.GrantThreshold, MenuRoot: c.MenuRoot}}, nil
+	}
+
+	raw, err := os.ReadFile(c.BotsFile)
+	if err != nil {
+		return nil, err
+	}
+	var bots []Bot
+	if err := json.Unmarshal(raw, &bots); err != nil {
+		return nil, fmt.Errorf("%s: %w", c.BotsFile, err)
+	}
+	if len(bots) == 0 {
+		return nil, fmt.Errorf("%s: нет ни одного бота", c.BotsFile)
+	}
+
+	seen := map[string]bool{}
+	for i := range bots {
+		b := &bots[i]
+		if !reBotName.MatchString(b.Name) {
+			return nil, fmt.Errorf("bot %d: имя %q должно состоять из a-z, 0-9, _ и -", i+1, b.Name)
+		}
+		if seen[b.Name] {
+			return nil, fmt.Errorf("bot %q указан дважды", b.Name)
+		}
+		seen[b.Name] = true
+
+		if b.Token == "" && b.TokenEnv != "" {
+			b.Token = os.Getenv(b.TokenEnv)
+		}
+		if b.Token == "" {
+			return nil, fmt.Errorf("bot %q: пустой token", b.Name)
+		}
+		switch b.DefaultLang {
+		case "":
+			b.DefaultLang = "ru"
+		case "ru", "kz":
+		default:
+			return nil, fmt.Errorf("bot %q: default_lang %q, ожидается ru или kz", b.Name, b.DefaultLang)
+		}
+		if b.Tenant == "" {
+			b.Tenant = DefaultTenant
+		}
+		if b.MenuRoot == "" {
+			b.MenuRoot = c.MenuRoot
+		}
+		switch {
+		case b.GrantThreshold == 0:
+			b.GrantThreshold = c.GrantThreshold
+		case b.GrantThreshold < 0 || b.GrantThreshold > 140:
+			return nil, fmt.Errorf("bot %q: grant_threshold %d, ожидается 1..140", b.Name, b.GrantThreshold)
+		}
+		if b.OffsetFile == "" {
+			b.OffsetFile = filepath.Join(filepath.Dir(c.OffsetFile), "offset-"+b.Name+".json")
+		}
+	}
+	return bots, nil
+}
@@ -1,19 +1,148 @@
 package config
 
-import "os"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
 
 type Config struct {
 	Token       string
+	BotsFile    string
+	APIEndpoint string
 	APIBase     string
 	NLPBase     string
 	DatabaseURL string
+
+	Workers   int
+	QueueSize int
+
+	Mode          string
+	WebhookURL    string
+	WebhookListen string
+	WebhookPath   string
+	WebhookSecret string
+
+	ShutdownTimeout time.Duration
+	OffsetFile      string
+
+	SessionTTL time.Duration
+	// SessionMax — сколько сессий всего хранят все боты вместе (в базе —
+	// вся таблица bot_sessions); сверх него вытесняются давно неактивные.
+	SessionMax   int
+	SessionSweep time.Duration
+	MetricsAddr  string
+
+	// RateLimitPerMin — апдейтов в минуту на пользователя; 0 — без ограничения.
+	RateLimitPerMin int
+	RateLimitBurst  int
+	Maintenance     bool
+	SlowHandler     time.Duration
+
+	// MenuRoot — код корневого узла меню в узлы_меню для ботов без своего menu_root.
+	MenuRoot string
+	// SynonymsRefresh — как часто перечитывать таблицу synonyms.
+	SynonymsRefresh time.Duration
+	// GrantThreshold — порог ЕНТ для конкурса на грант, если у бота не задан свой.
+	GrantThreshold int
 }
 
 func FromEnv() Config {
 	return Config{
-		Token:   os.Getenv("TELEGRAM_TOKEN"),
-		APIBase: os.Getenv("CONTENT_API_URL"),
-		NLPBase: os.Getenv("NLP_API_URL"),
-		//DatabaseURL: os.Getenv("DATABASE_URL"),
+		Token:       os.Getenv("TELEGRAM_TOKEN"),
+		BotsFile:    os.Getenv("BOTS_FILE"),
+		APIEndpoint: envStr("TELEGRAM_API_ENDPOINT", "https://api.telegram.org/bot%s/%s"),
+		APIBase:     os.Getenv("CONTENT_API_URL"),
+		NLPBase:     os.Getenv("NLP_API_URL"),
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+
+		Workers:   envInt("BOT_WORKERS", 8),
+		QueueSize: envInt("BOT_QUEUE_SIZE", 64),
+
+		Mode:          envStr("BOT_MODE", "polling"),
+		WebhookURL:    os.Getenv("WEBHOOK_URL"),
+		WebhookListen: envStr("WEBHOOK_LISTEN", ":8443"),
+		WebhookPath:   envStr("WEBHOOK_PATH", "/telegram/webhook"),
+		WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
+
+		ShutdownTimeout: envDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		OffsetFile:      envStr("OFFSET_FILE", "data/offset.json"),
+
+		SessionTTL:   envDuration("SESSION_TTL", 30*24*time.Hour),
+		SessionMax:   envInt("SESSION_MAX", 100000),
+		SessionSweep: envDuration("SESSION_SWEEP", 5*time.Minute),
+		MetricsAddr:  os.Getenv("METRICS_ADDR"),
+
+		RateLimitPerMin: envLimit("RATE_LIMIT_PER_MIN", 20),
+		RateLimitBurst:  envInt("RATE_LIMIT_BURST", 5),
+		Maintenance:     envBool("MAINTENANCE_MODE"),
+		SlowHandler:     envDuration("SLOW_HANDLER", 5*time.Second),
+
+		MenuRoot:        envStr("MENU_ROOT", "main"),
+		SynonymsRefresh: envDuration("SYNONYMS_REFRESH", 5*time.Minute),
+		GrantThreshold:  envInt("GRANT_THRESHOLD", 50),
+	}
+}
+
+// Validate проверяет настройки, без которых бот запускать нельзя.
+func (c Config) Validate() error {
+	if c.APIBase == "" || c.NLPBase == "" {
+		return errors.New("CONTENT_API_URL или NLP_API_URL пустые")
+	}
+	switch c.Mode {
+	case "polling":
+	case "webhook":
+		// без секрета webhook принимает апдейты от кого угодно
+		if c.WebhookSecret == "" {
+			return errors.New("BOT_MODE=webhook требует WEBHOOK_SECRET")
+		}
+	default:
+		return fmt.Errorf("BOT_MODE=%q: ожидается polling или webhook", c.Mode)
+	}
+	return nil
+}
+
+func envBool(key string) bool {
+	v, _ := strconv.ParseBool(os.Getenv(key))
+	return v
+}
+
+func envStr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// envLimit — как envInt, но явно заданные 0 или пустое значение означают
+// «без ограничения»; default только если переменной нет вовсе.
+func envLimit(key string, def int) int {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return max(n, 0)
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil && v > 0 {
+		return v
 	}
+	return def
 }
@@ -0,0 +1,162 @@
+package dispatcher
+
+import (
+	"context"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Dispatcher раздаёт апдейты пулу воркеров. У каждого чата своя очередь, и
+// чат обрабатывает не больше одного воркера за раз, поэтому апдейты чата
+// идут строго по порядку. Следующий чат с апдейтами берёт любой свободный
+// воркер: медленный ответ NLP держит только свой чат, а не очередь соседей.
+type Dispatcher struct {
+	handle  func(tgbotapi.Update)
+	workers int
+	// limit — сколько апдейтов всего может ждать воркера.
+	limit int
+	wg    sync.WaitGroup
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond // появился чат, готовый к обработке, или Shutdown
+	notFull  *sync.Cond // освободилось место в очереди или Shutdown
+	chats    map[int64]*chatQueue
+	ready    []int64 // чаты с апдейтами, которые сейчас никто не обрабатывает
+	queued   int
+	stopped  bool
+}
+
+type chatQueue struct {
+	pending []tgbotapi.Update
+	busy    bool // апдейт чата сейчас у воркера
+}
+
+// New создаёт пул из workers воркеров; ждать обработки могут до
+// workers*queueSize апдейтов.
+func New(workers, queueSize int, handle func(tgbotapi.Update)) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	d := &Dispatcher{handle: handle, workers: workers, limit: workers * queueSize, chats: map[int64]*chatQueue{}}
+	d.notEmpty = sync.NewCond(&d.mu)
+	d.notFull = sync.NewCond(&d.mu)
+	return d
+}
+
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		d.mu.Lock()
+		for len(d.ready) == 0 && !d.stopped {
+			d.notEmpty.Wait()
+		}
+		if len(d.ready) == 0 {
+			// остановлены, а оставшиеся апдейты дообработают воркеры их чатов
+			d.mu.Unlock()
+			return
+		}
+		key := d.ready[0]
+		d.ready = d.ready[1:]
+		c := d.chats[key]
+		upd := c.pending[0]
+		c.pending = c.pending[1:]
+		c.busy = true
+		d.queued--
+		d.notFull.Signal()
+		d.mu.Unlock()
+
+		d.handle(upd)
+
+		d.mu.Lock()
+		c.busy = false
+		if len(c.pending) > 0 {
+			// в конец: другие чаты не ждут, пока этот разберёт всю очередь
+			d.ready = append(d.ready, key)
+			d.notEmpty.Signal()
+		} else {
+			delete(d.chats, key)
+		}
+		d.mu.Unlock()
+	}
+}
+
+// Dispatch ставит апдейт в очередь его чата. Если очередь заполнена,
+// вызов блокируется — это естественный backpressure для long polling, —
+// но не дольше, чем до Shutdown. Непринятый апдейт (false) не
+// подтверждается и придёт снова после перезапуска.
+func (d *Dispatcher) Dispatch(upd tgbotapi.Update) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for d.queued >= d.limit && !d.stopped {
+		d.notFull.Wait()
+	}
+	if d.stopped {
+		return false
+	}
+	key := chatKey(upd)
+	c := d.chats[key]
+	if c == nil {
+		c = &chatQueue{}
+		d.chats[key] = c
+	}
+	c.pending = append(c.pending, upd)
+	d.queued++
+	if !c.busy && len(c.pending) == 1 {
+		d.ready = append(d.ready, key)
+		d.notEmpty.Signal()
+	}
+	return true
+}
+
+// Stop перестаёт принимать апдейты и ждёт, пока воркеры дообработают всё,
+// что уже принято.
+func (d *Dispatcher) Stop() {
+	d.Shutdown(context.Background())
+}
+
+// Shutdown перестаёт принимать апдейты и ждёт воркеров не дольше, чем
+// живёт ctx. Апдейты, уже стоящие в очередях, тоже дообрабатываются.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	d.stopped = true
+	// будим и ждущие места Dispatch, и простаивающих воркеров
+	d.notFull.Broadcast()
+	d.notEmpty.Broadcast()
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func chatKey(upd tgbotapi.Update) int64 {
+	if upd.CallbackQuery != nil && upd.CallbackQuery.Message == nil {
+		return upd.CallbackQuery.From.ID
+	}
+	if c := upd.FromChat(); c != nil {
+		return c.ID
+	}
+	if u := upd.SentFrom(); u != nil {
+		return u.ID
+	}
+	return int64(upd.UpdateID)
+}
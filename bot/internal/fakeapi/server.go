@@ -0,0 +1,211 @@
+package fakeapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Server — минимальная имитация Telegram Bot API. Бот подключается к нему
+// через tgbotapi.NewBotAPIWithAPIEndpoint(token, Endpoint(url)), а тест или
+// разработчик подкладывает апдейты через Push и читает ответы через Sent.
+type Server struct {
+	Bot tgbotapi.User
+
+	mu      sync.Mutex
+	updates []tgbotapi.Update
+	nextUpd int
+	nextMsg int
+	sent    []Sent
+	notify  chan struct{}
+}
+
+// Sent — один запрос бота к API, кроме служебных getMe/getUpdates.
+type Sent struct {
+	Method string            `json:"method"`
+	ChatID int64             `json:"chat_id,omitempty"`
+	Text   string            `json:"text,omitempty"`
+	Params map[string]string `json:"params"`
+	File   string            `json:"file,omitempty"`
+	At     time.Time         `json:"at"`
+}
+
+func New() *Server {
+	return &Server{
+		Bot:    tgbotapi.User{ID: 1, IsBot: true, FirstName: "Talapker", UserName: "talapker_test_bot"},
+		notify: make(chan struct{}),
+	}
+}
+
+// Endpoint превращает базовый URL сервера в формат, который ждёт tgbotapi.
+func Endpoint(baseURL string) string {
+	return strings.TrimRight(baseURL, "/") + "/bot%s/%s"
+}
+
+// Push ставит апдейт в очередь getUpdates, присваивая ему update_id.
+func (s *Server) Push(upd tgbotapi.Update) tgbotapi.Update {
+	s.mu.Lock()
+	s.nextUpd++
+	upd.UpdateID = s.nextUpd
+	s.updates = append(s.updates, upd)
+	close(s.notify)
+	s.notify = make(chan struct{})
+	s.mu.Unlock()
+	return upd
+}
+
+// PushText — сокращение для текстового сообщения от пользователя в личном чате.
+func (s *Server) PushText(chatID int64, text string) tgbotapi.Update {
+	s.mu.Lock()
+	s.nextMsg++
+	id := s.nextMsg
+	s.mu.Unlock()
+	return s.Push(tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: id,
+		Date:      int(time.Now().Unix()),
+		From:      &tgbotapi.User{ID: chatID, FirstName: "user"},
+		Chat:      &tgbotapi.Chat{ID: chatID, Type: "private"},
+		Text:      text,
+		Entities:  commandEntities(text),
+	}})
+}
+
+func (s *Server) Sent() []Sent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Sent(nil), s.sent...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /bot<token>/<method>
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "bot") {
+		http.NotFound(w, r)
+		return
+	}
+	method := parts[1]
+	if err := r.ParseMultipartForm(10 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch method {
+	case "getMe":
+		writeResult(w, s.Bot)
+	case "getUpdates":
+		writeResult(w, s.getUpdates(r))
+	case "sendMessage", "editMessageText", "sendDocument":
+		writeResult(w, s.record(method, r))
+	case "editMessageReplyMarkup", "deleteMessage", "answerCallbackQuery",
+		"setMyCommands", "setWebhook", "deleteWebhook":
+		s.record(method, r)
+		writeResult(w, true)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found: method "+method+" is not emulated")
+	}
+}
+
+func (s *Server) getUpdates(r *http.Request) []tgbotapi.Update {
+	offset, _ := strconv.Atoi(r.FormValue("offset"))
+	timeout, _ := strconv.Atoi(r.FormValue("timeout"))
+	deadline := time.After(time.Duration(timeout) * time.Second)
+	for {
+		s.mu.Lock()
+		var out []tgbotapi.Update
+		kept := s.updates[:0]
+		for _, u := range s.updates {
+			if u.UpdateID >= offset {
+				out = append(out, u)
+				kept = append(kept, u)
+			}
+		}
+		s.updates = kept
+		wait := s.notify
+		s.mu.Unlock()
+
+		if len(out) > 0 || timeout <= 0 {
+			return out
+		}
+		select {
+		case <-wait:
+		case <-deadline:
+			return nil
+		case <-r.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *Server) record(method string, r *http.Request) tgbotapi.Message {
+	params := map[string]string{}
+	for k, v := range r.Form {
+		if len(v) > 0 {
+			params[k] = v[0]
+		}
+	}
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+	text := params["text"]
+	if method == "sendDocument" {
+		text = params["caption"]
+	}
+	sent := Sent{Method: method, ChatID: chatID, Text: text, Params: params, At: time.Now()}
+	if r.MultipartForm != nil {
+		for _, fhs := range r.MultipartForm.File {
+			for _, fh := range fhs {
+				sent.File = fh.Filename
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.sent = append(s.sent, sent)
+	msgID, _ := strconv.Atoi(params["message_id"])
+	if msgID == 0 {
+		s.nextMsg++
+		msgID = s.nextMsg
+	}
+	s.mu.Unlock()
+
+	bot := s.Bot
+	return tgbotapi.Message{
+		MessageID: msgID,
+		From:      &bot,
+		Date:      int(sent.At.Unix()),
+		Chat:      &tgbotapi.Chat{ID: chatID, Type: "private"},
+		Text:      text,
+	}
+}
+
+func commandEntities(text string) []tgbotapi.MessageEntity {
+	if !strings.HasPrefix(text, "/") {
+		return nil
+	}
+	n := strings.IndexAny(text, " \n")
+	if n < 0 {
+		n = len(text)
+	}
+	// Telegram считает длину в UTF-16, команды же всегда в ASCII.
+	return []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: n}}
+}
+
+func writeResult(w http.ResponseWriter, v any) {
+	res, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: res})
+}
+
+func writeError(w http.ResponseWriter, code int, desc string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: false, ErrorCode: code, Description: desc})
+}
@@ -0,0 +1,101 @@
+package fakeapi
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func newClient(t *testing.T) (*Server, *tgbotapi.BotAPI) {
+	t.Helper()
+	srv := New()
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint("123:test", Endpoint(ts.URL))
+	if err != nil {
+		t.Fatalf("getMe: %v", err)
+	}
+	if api.Self.UserName != srv.Bot.UserName {
+		t.Fatalf("self = %q, want %q", api.Self.UserName, srv.Bot.UserName)
+	}
+	return srv, api
+}
+
+func TestGetUpdates(t *testing.T) {
+	srv, api := newClient(t)
+	first := srv.PushText(42, "/start")
+	srv.PushText(42, "привет")
+
+	upds, err := api.GetUpdates(tgbotapi.UpdateConfig{Offset: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upds) != 2 {
+		t.Fatalf("got %d updates, want 2", len(upds))
+	}
+	if upds[0].UpdateID != first.UpdateID || !upds[0].Message.IsCommand() || upds[0].Message.Command() != "start" {
+		t.Fatalf("first update = %+v, want /start command", upds[0].Message)
+	}
+	if upds[1].Message.Chat.ID != 42 || upds[1].Message.Text != "привет" {
+		t.Fatalf("second update = %+v", upds[1].Message)
+	}
+
+	// offset подтверждает полученное
+	upds, err = api.GetUpdates(tgbotapi.UpdateConfig{Offset: upds[1].UpdateID + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upds) != 0 {
+		t.Fatalf("got %d updates after offset, want 0", len(upds))
+	}
+}
+
+func TestRecordsSendAndEdit(t *testing.T) {
+	srv, api := newClient(t)
+
+	msg, err := api.Send(tgbotapi.NewMessage(42, "Выберите раздел:"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.MessageID == 0 || msg.Chat.ID != 42 {
+		t.Fatalf("sendMessage result = %+v", msg)
+	}
+	if _, err := api.Send(tgbotapi.NewEditMessageText(42, msg.MessageID, "Гранты")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.Request(tgbotapi.NewCallback("cb1", "")); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := srv.Sent()
+	want := []struct {
+		method string
+		text   string
+	}{
+		{"sendMessage", "Выберите раздел:"},
+		{"editMessageText", "Гранты"},
+		{"answerCallbackQuery", ""},
+	}
+	if len(sent) != len(want) {
+		t.Fatalf("recorded %d requests, want %d: %+v", len(sent), len(want), sent)
+	}
+	for i, w := range want {
+		if sent[i].Method != w.method || sent[i].Text != w.text {
+			t.Errorf("sent[%d] = %s %q, want %s %q", i, sent[i].Method, sent[i].Text, w.method, w.text)
+		}
+	}
+	if sent[0].ChatID != 42 || sent[1].ChatID != 42 {
+		t.Errorf("chat ids = %d, %d, want 42", sent[0].ChatID, sent[1].ChatID)
+	}
+	if sent[1].Params["message_id"] != "1" {
+		t.Errorf("edit message_id = %q, want %q", sent[1].Params["message_id"], "1")
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	_, api := newClient(t)
+	if _, err := api.MakeRequest("sendSticker", tgbotapi.Params{"chat_id": "42"}); err == nil {
+		t.Fatal("expected an error for a method the fake does not emulate")
+	}
+}
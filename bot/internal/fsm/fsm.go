@@ -0,0 +1,236 @@
+package fsm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"telegramBot/bot/internal/session"
+)
+
+type InputKind int
+
+const (
+	InputText InputKind = iota
+	InputNumber
+	InputChoice
+)
+
+// Choice — вариант ответа: Value сохраняется в данных диалога,
+// Labels — подписи кнопок по языкам.
+type Choice struct {
+	Value  string
+	Labels map[string]string
+}
+
+func (c Choice) Label(lang string) string {
+	if l := c.Labels[lang]; l != "" {
+		return l
+	}
+	if l := c.Labels["ru"]; l != "" {
+		return l
+	}
+	return c.Value
+}
+
+// State — один шаг диалога: что спросить, какой ответ ждать и куда идти дальше.
+type State struct {
+	Prompt  map[string]string
+	Input   InputKind
+	Choices []Choice
+	// Min и Max — границы для InputNumber.
+	Min, Max int
+	// MaxLen — ограничение длины для InputText, 0 — 200 символов.
+	MaxLen int
+	// Optional разрешает пропустить шаг кнопкой SkipLabel; значение будет пустым.
+	Optional bool
+	// Key — под каким ключом сохранить ответ; по умолчанию имя состояния.
+	Key string
+	// Next выбирает следующее состояние по уже собранным данным;
+	// nil или "" завершают диалог.
+	Next func(data map[string]string) string
+}
+
+// Flow — пошаговый диалог. Finish получает собранные ответы, может записать
+// их в сессию и возвращает итоговое сообщение.
+type Flow struct {
+	Name    string
+	Start   string
+	States  map[string]State
+	Timeout time.Duration
+	Finish  func(lang string, data map[string]string, s *session.Session) string
+}
+
+// Reply — что бот должен отправить после шага.
+type Reply struct {
+	Text    string
+	Choices []string
+	Done    bool
+}
+
+const defaultTimeout = 10 * time.Minute
+
+var SkipLabel = map[string]string{"ru": "Пропустить", "kz": "Өткізіп жіберу"}
+
+type Engine struct {
+	flows map[string]*Flow
+}
+
+func New() *Engine {
+	return &Engine{flows: map[string]*Flow{}}
+}
+
+func (e *Engine) Register(f *Flow) {
+	if _, ok := f.States[f.Start]; !ok {
+		panic(fmt.Sprintf("fsm: flow %q: unknown start state %q", f.Name, f.Start))
+	}
+	e.flows[f.Name] = f
+}
+
+// Start начинает диалог заново, даже если другой ещё не закончен.
+func (e *Engine) Start(s *session.Session, name, lang string, now time.Time) (Reply, error) {
+	f, ok := e.flows[name]
+	if !ok {
+		return Reply{}, fmt.Errorf("fsm: unknown flow %q", name)
+	}
+	s.Flow = &session.FlowState{Name: name, State: f.Start, Data: map[string]string{}, Expires: now.Add(f.timeout())}
+	return prompt(f.States[f.Start], lang, ""), nil
+}
+
+// Active сообщает, идёт ли в сессии диалог; просроченный диалог сбрасывается.
+func (e *Engine) Active(s *session.Session, now time.Time) bool {
+	if s.Flow == nil {
+		return false
+	}
+	if _, ok := e.flows[s.Flow.Name]; !ok || now.After(s.Flow.Expires) {
+		s.Flow = nil
+		return false
+	}
+	return true
+}
+
+// Step обрабатывает ответ пользователя. handled=false — диалога нет
+// (или он истёк), и сообщение нужно обработать как обычно.
+func (e *Engine) Step(s *session.Session, input, lang string, now time.Time) (reply Reply, handled bool) {
+	if !e.Active(s, now) {
+		return Reply{}, false
+	}
+	f := e.flows[s.Flow.Name]
+	st, ok := f.States[s.Flow.State]
+	if !ok {
+		s.Flow = nil
+		return Reply{}, false
+	}
+
+	value, problem := parse(st, strings.TrimSpace(input), lang)
+	if problem != "" {
+		return prompt(st, lang, problem), true
+	}
+	key := st.Key
+	if key == "" {
+		key = s.Flow.State
+	}
+	if s.Flow.Data == nil {
+		s.Flow.Data = map[string]string{}
+	}
+	s.Flow.Data[key] = value
+
+	next := ""
+	if st.Next != nil {
+		next = st.Next(s.Flow.Data)
+	}
+	if nst, ok := f.States[next]; ok {
+		s.Flow.State = next
+		s.Flow.Expires = now.Add(f.timeout())
+		return prompt(nst, lang, ""), true
+	}
+
+	data := s.Flow.Data
+	s.Flow = nil
+	text := ""
+	if f.Finish != nil {
+		text = f.Finish(lang, data, s)
+	}
+	return Reply{Text: text, Done: true}, true
+}
+
+// Cancel прерывает диалог; false — прерывать было нечего.
+func Cancel(s *session.Session) bool {
+	if s.Flow == nil {
+		return false
+	}
+	s.Flow = nil
+	return true
+}
+
+func (f *Flow) timeout() time.Duration {
+	if f.Timeout > 0 {
+		return f.Timeout
+	}
+	return defaultTimeout
+}
+
+func prompt(st State, lang, problem string) Reply {
+	text := st.Prompt[lang]
+	if text == "" {
+		text = st.Prompt["ru"]
+	}
+	if problem != "" {
+		text = problem + "\n\n" + text
+	}
+	r := Reply{Text: text}
+	for _, c := range st.Choices {
+		r.Choices = append(r.Choices, c.Label(lang))
+	}
+	if st.Optional {
+		r.Choices = append(r.Choices, SkipLabel[lang])
+	}
+	return r
+}
+
+func parse(st State, in, lang string) (value, problem string) {
+	if st.Optional && (in == SkipLabel[lang] || in == SkipLabel["ru"] || in == SkipLabel["kz"]) {
+		return "", ""
+	}
+	kz := lang == "kz"
+	switch st.Input {
+	case InputNumber:
+		n, err := strconv.Atoi(in)
+		if err != nil || n < st.Min || n > st.Max {
+			if kz {
+				return "", fmt.Sprintf("%d-ден %d-ге дейінгі санды енгізіңіз.", st.Min, st.Max)
+			}
+			return "", fmt.Sprintf("Введите число от %d до %d.", st.Min, st.Max)
+		}
+		return strconv.Itoa(n), ""
+	case InputChoice:
+		for _, c := range st.Choices {
+			if strings.EqualFold(in, c.Label(lang)) || strings.EqualFold(in, c.Value) {
+				return c.Value, ""
+			}
+			for _, l := range c.Labels {
+				if strings.EqualFold(in, l) {
+					return c.Value, ""
+				}
+			}
+		}
+		if kz {
+			return "", "Төмендегі нұсқалардың бірін таңдаңыз."
+		}
+		return "", "Выберите один из вариантов ниже."
+	default:
+		max := st.MaxLen
+		if max <= 0 {
+			max = 200
+		}
+		if in == "" || utf8.RuneCountInString(in) > max {
+			if kz {
+				return "", fmt.Sprintf("Жауап бос болмауы және %d таңбадан аспауы керек.", max)
+			}
+			return "", fmt.Sprintf("Ответ не должен быть пустым и длиннее %d символов.", max)
+		}
+		return in, ""
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"telegramBot/bot/internal/analytics"
+	"telegramBot/bot/internal/lang"
+	"telegramBot/bot/internal/router"
+	"telegramBot/bot/internal/session"
+)
+
+// Deep-link: t.me/<bot>?start=<payload>. Payload — части через "_":
+//
+//	ru, kz        — язык;
+//	src-<кампания> — источник (сайт, плакат), только латиница, цифры и "-";
+//	остальное     — код узла меню или slug контента.
+//
+// Например grants_kz_src-poster1 откроет гранты на казахском и запишет
+// источник poster1.
+type startLink struct {
+	Lang   string
+	Target string
+	Source string
+}
+
+// Telegram допускает в start-параметре только эти символы.
+var reStartPayload = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+func parseStart(payload string) (startLink, bool) {
+	var l startLink
+	if !reStartPayload.MatchString(payload) {
+		return l, false
+	}
+	var rest []string
+	for _, t := range strings.Split(strings.ToLower(payload), "_") {
+		switch {
+		case t == "ru" || t == "kz" || t == "kk":
+			l.Lang = lang.FromCode(t)
+		case strings.HasPrefix(t, "src-"):
+			l.Source = strings.TrimPrefix(t, "src-")
+		case t != "":
+			rest = append(rest, t)
+		}
+	}
+	l.Target = strings.Join(rest, "_")
+	return l, true
+}
+
+// startLink обрабатывает /start с payload. false — в ссылке нет ни языка,
+// ни раздела, и нужен обычный выбор языка.
+func (b *Bot) startLink(c *router.Context) bool {
+	link, ok := parseStart(c.Args)
+	if !ok {
+		return false
+	}
+	b.recordStart(c.UserID, link.Source, c.Args)
+	if link.Lang == "" && link.Target == "" {
+		return false
+	}
+
+	l, auto := link.Lang, false
+	if l == "" {
+		auto = true
+		if m := c.Update.Message; m != nil && m.From != nil {
+			l = lang.FromCode(m.From.LanguageCode)
+		}
+		if l == "" {
+			l = b.langFrom(session.Session{})
+		}
+	}
+	b.resetSession(c.ChatID, l)
+	if auto {
+		b.updateSession(c.ChatID, func(s *session.Session) { s.LangAuto = true })
+	}
+
+	if link.Target == "" {
+		b.showRoot(c.ChatID, l)
+		return true
+	}
+	if _, err := b.screen(link.Target, l); err == nil {
+		b.updateSession(c.ChatID, func(s *session.Session) {
+			s.Node = link.Target
+			if link.Target != b.root() {
+				s.NavStack = []string{b.root()}
+			}
+		})
+		b.render(c.ChatID, 0, link.Target, l, 0)
+		return true
+	}
+	if _, err := b.APICl.Get(link.Target, l); err == nil {
+		b.sendFromAPI(c.ChatID, link.Target, l)
+	} else {
+		log.Printf("start link %q: unknown target %q", c.Args, link.Target)
+	}
+	b.showRoot(c.ChatID, l)
+	return true
+}
+
+func (b *Bot) recordStart(userID int64, source, payload string) {
+	if b.Analytics == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sessionTimeout)
+	defer cancel()
+	e := analytics.Event{UserID: userID, Kind: analytics.KindStart, Source: source, Payload: payload, At: time.Now()}
+	if err := b.Analytics.Record(ctx, e); err != nil {
+		log.Printf("analytics: %v", err)
+	}
+}
+
+// analyticsData отдаёт в privacy.Registry события пользователя.
+type analyticsData struct{ b *Bot }
+
+func (analyticsData) Name() string { return "analytics" }
+
+func (d analyticsData) Export(ctx context.Context, userID int64) (any, error) {
+	if d.b.Analytics == nil {
+		return nil, nil
+	}
+	events, err := d.b.Analytics.Events(ctx, userID)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (d analyticsData) Erase(ctx context.Context, userID int64) error {
+	if d.b.Analytics == nil {
+		return nil
+	}
+	return d.b.Analytics.Erase(ctx, userID)
+}
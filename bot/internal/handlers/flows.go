@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBot/bot/internal/fsm"
+	keyboard "telegramBot/bot/internal/keybord"
+	"telegramBot/bot/internal/router"
+	"telegramBot/bot/internal/session"
+)
+
+var cancelLabel = map[string]string{"ru": "✖️ Отмена", "kz": "✖️ Болдырмау"}
+
+var programs = []fsm.Choice{
+	{Value: "agronomy", Labels: map[string]string{"ru": "Агрономия", "kz": "Агрономия"}},
+	{Value: "veterinary", Labels: map[string]string{"ru": "Ветеринария", "kz": "Ветеринария"}},
+	{Value: "engineering", Labels: map[string]string{"ru": "Инженерия и техника", "kz": "Инженерия және техника"}},
+	{Value: "it", Labels: map[string]string{"ru": "IT", "kz": "IT"}},
+	{Value: "economics", Labels: map[string]string{"ru": "Экономика", "kz": "Экономика"}},
+}
+
+func programLabel(value, lang string) string {
+	for _, p := range programs {
+		if p.Value == value {
+			return p.Label(lang)
+		}
+	}
+	return value
+}
+
+func (b *Bot) flows() *fsm.Engine {
+	e := fsm.New()
+	e.Register(&fsm.Flow{
+		Name:  "grant",
+		Start: "program",
+		States: map[string]fsm.State{
+			"program": {
+				Prompt: map[string]string{
+					"ru": "Какая программа вас интересует?",
+					"kz": "Сізді қай бағдарлама қызықтырады?",
+				},
+				Input:   fsm.InputChoice,
+				Choices: programs,
+				Next:    func(map[string]string) string { return "ent" },
+			},
+			"ent": {
+				Prompt: map[string]string{
+					"ru": "Сколько баллов ЕНТ у вас (или сколько ожидаете)?",
+					"kz": "ҰБТ балыңыз қанша (немесе қанша күтесіз)?",
+				},
+				Input: fsm.InputNumber,
+				Min:   0,
+				Max:   140,
+			},
+		},
+		Finish: b.grantResult,
+	})
+	e.Register(b.profileFlow())
+	return e
+}
+
+func (b *Bot) grantResult(lang string, data map[string]string, _ *session.Session) string {
+	ent, _ := strconv.Atoi(data["ent"])
+	grantThreshold := b.GrantThreshold
+	program := programLabel(data["program"], lang)
+	if lang == "kz" {
+		if ent < grantThreshold {
+			return fmt.Sprintf("%d балл гранттық конкурстың шекті балынан (%d) төмен. «%s» бағдарламасына ақылы негізде түсуге болады — толығырақ «🎁 Гранттар» бөлімінде.", ent, grantThreshold, program)
+		}
+		return fmt.Sprintf("%d балмен «%s» бағдарламасы бойынша грантқа конкурсқа қатыса аласыз. Өткен жылдардағы өту балдарын қабылдау комиссиясынан нақтылаңыз.", ent, program)
+	}
+	if ent < grantThreshold {
+		return fmt.Sprintf("%d баллов — ниже порога конкурса на грант (%d). На программу «%s» можно поступить на платной основе — подробнее в разделе «🎁 Гранты».", ent, grantThreshold, program)
+	}
+	return fmt.Sprintf("С %d баллами вы можете участвовать в конкурсе на грант по программе «%s». Проходные баллы прошлых лет уточняйте в приёмной комиссии.", ent, program)
+}
+
+func (b *Bot) startFlow(name string) router.HandlerFunc {
+	return func(c *router.Context) {
+		var (
+			r    fsm.Reply
+			lang string
+			err  error
+		)
+		b.updateSession(c.ChatID, func(s *session.Session) {
+			lang = b.langFrom(*s)
+			s.Smalltalk = 0
+			r, err = b.Flows.Start(s, name, lang, time.Now())
+		})
+		if err != nil {
+			b.API.Send(tgbotapi.NewMessage(c.ChatID, "Данные скоро обновим."))
+			return
+		}
+		b.sendFlowReply(c.ChatID, lang, r)
+	}
+}
+
+// stepFlow перехватывает сообщения, пока в чате идёт пошаговый диалог.
+func (b *Bot) stepFlow(c *router.Context) bool {
+	if b.session(c.ChatID).Flow == nil {
+		return false
+	}
+	if c.Text == cancelLabel["ru"] || c.Text == cancelLabel["kz"] {
+		b.handleCancel(c)
+		return true
+	}
+	var (
+		r       fsm.Reply
+		lang    string
+		handled bool
+	)
+	b.updateSession(c.ChatID, func(s *session.Session) {
+		lang = b.langFrom(*s)
+		r, handled = b.Flows.Step(s, c.Text, lang, time.Now())
+	})
+	if !handled {
+		return false
+	}
+	b.sendFlowReply(c.ChatID, lang, r)
+	return true
+}
+
+func (b *Bot) handleCancel(c *router.Context) {
+	var cancelled bool
+	var lang string
+	b.updateSession(c.ChatID, func(s *session.Session) {
+		lang = b.langFrom(*s)
+		cancelled = fsm.Cancel(s)
+	})
+	text := "Нечего отменять."
+	if lang == "kz" {
+		text = "Болдыратын ештеңе жоқ."
+	}
+	if !cancelled {
+		b.API.Send(tgbotapi.NewMessage(c.ChatID, text))
+		return
+	}
+	text = "Хорошо, прервали."
+	if lang == "kz" {
+		text = "Жарайды, тоқтаттық."
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, text)
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	b.API.Send(msg)
+	b.showRoot(c.ChatID, lang)
+}
+
+// sendFlowReply показывает варианты ответа кнопками и всегда оставляет
+// кнопку отмены; после последнего шага убирает клавиатуру и возвращает
+// главное меню.
+func (b *Bot) sendFlowReply(chatID int64, lang string, r fsm.Reply) {
+	msg := tgbotapi.NewMessage(chatID, r.Text)
+	if r.Done {
+		msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	} else {
+		cols := 1
+		if len(r.Choices) > 4 {
+			cols = 2
+		}
+		msg.ReplyMarkup = keyboard.MenuGrid(append(r.Choices, cancelLabel[lang]), cols)
+	}
+	b.API.Send(msg)
+	if r.Done {
+		b.showRoot(chatID, lang)
+	}
+}
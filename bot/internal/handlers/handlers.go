@@ -2,82 +2,66 @@ package handlers
 
 import (
 	"regexp"
-	"strconv"
 	"strings"
-	"sync"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"telegramBot/bot/internal/analytics"
 	contentclient "telegramBot/bot/internal/client"
-	keyboard "telegramBot/bot/internal/keybord"
+	"telegramBot/bot/internal/fsm"
+	"telegramBot/bot/internal/lang"
+	"telegramBot/bot/internal/menu"
 	"telegramBot/bot/internal/nlpclient"
+	"telegramBot/bot/internal/privacy"
+	"telegramBot/bot/internal/profile"
+	"telegramBot/bot/internal/resolver"
+	"telegramBot/bot/internal/router"
+	"telegramBot/bot/internal/sender"
+	"telegramBot/bot/internal/session"
 )
 
 const smalltalkWindow = 1
 const classifyThreshold = 0.65
 
-func stKey(chatID int64) string { return "st:" + strconv.FormatInt(chatID, 10) }
-
 type Bot struct {
-	API     *tgbotapi.BotAPI
-	Store   sync.Map
-	History sync.Map
+	API      sender.Sender
+	Sessions session.Store
 
 	APICl *contentclient.Client
 	NLP   *nlpclient.Client
-}
 
-func New(api *tgbotapi.BotAPI, apiCl *contentclient.Client, nlp *nlpclient.Client) *Bot {
-	return &Bot{API: api, APICl: apiCl, NLP: nlp}
-}
+	// DefaultLang — язык чата, пока пользователь его не выбрал.
+	DefaultLang string
+	// GrantThreshold — минимальный балл ЕНТ для конкурса на грант.
+	GrantThreshold int
 
-func (b *Bot) langOf(chatID int64) string {
-	if v, ok := b.Store.Load(chatID); ok {
-		if s, ok := v.(string); ok && (s == "ru" || s == "kz") {
-			return s
-		}
-	}
-	return "ru"
-}
+	// Menu — источник экранов меню, MenuRoot — код корневого узла.
+	Menu     menu.Source
+	MenuRoot string
 
-func (b *Bot) getHistory(chatID int64) []map[string]string {
-	if v, ok := b.History.Load(chatID); ok {
-		if h, ok := v.([]map[string]string); ok {
-			return h
-		}
-	}
-	return nil
-}
+	Router *router.Router
+	Flows  *fsm.Engine
 
-func (b *Bot) pushUser(chatID int64, text string) {
-	h := b.getHistory(chatID)
-	h = append(h, map[string]string{"role": "user", "content": text})
-	if len(h) > 8 {
-		h = h[len(h)-8:]
-	}
-	b.History.Store(chatID, h)
-}
+	// Privacy — все хранилища с данными пользователя для /mydata и /forget.
+	Privacy   *privacy.Registry
+	Analytics analytics.Store
+	// Profiles — профили абитуриентов по ID пользователя.
+	Profiles profile.Store
 
-func (b *Bot) pushAssistant(chatID int64, text string) {
-	h := b.getHistory(chatID)
-	h = append(h, map[string]string{"role": "assistant", "content": text})
-	if len(h) > 8 {
-		h = h[len(h)-8:]
-	}
-	b.History.Store(chatID, h)
+	// Resolver узнаёт раздел в набранном тексте раньше, чем спрашивать NLP.
+	Resolver *resolver.Resolver
 }
 
-func (b *Bot) enterSmalltalk(chatID int64) {
-	b.Store.Store(stKey(chatID), smalltalkWindow)
-}
-func (b *Bot) inSmalltalk(chatID int64) bool {
-	if v, ok := b.Store.Load(stKey(chatID)); ok {
-		if n, ok := v.(int); ok && n > 0 {
-			b.Store.Store(stKey(chatID), n-1)
-			return true
-		}
-	}
-	return false
+func New(api sender.Sender, apiCl *contentclient.Client, nlp *nlpclient.Client) *Bot {
+	b := &Bot{API: api, Sessions: session.NewMemory(0, 0), APICl: apiCl, NLP: nlp, DefaultLang: "ru", GrantThreshold: 50,
+		Menu: staticMenu, MenuRoot: "main", Analytics: analytics.NewMemory(10000), Profiles: profile.NewMemory(), Resolver: newResolver()}
+	b.Privacy = privacy.New("", privacy.LogAudit{})
+	b.Privacy.Register(sessionData{b})
+	b.Privacy.Register(analyticsData{b})
+	b.Privacy.Register(profileData{b})
+	b.Flows = b.flows()
+	b.Router = b.routes()
+	return b
 }
 
 var reHello = regexp.MustCompile(`(?i)\b(салам|салем|сәлем|прив(?:ет)?|здар(?:ова|ов)|эй|ей|хей|hey|hi|hello|ассалаумағалейкум|ассалам|сәлеметсіз бе|салям|салямалейкум|здоровченко)\b`)
@@ -130,83 +114,51 @@ func cannedSmalltalk(lang string) string {
 }
 
 func (b *Bot) HandleMessage(upd tgbotapi.Update) {
-	if upd.Message == nil {
+	b.detectLang(upd)
+	b.Router.Handle(upd)
+}
+
+// detectLang выставляет язык чата, если пользователь не выбрал его сам:
+// сначала по тексту сообщения, а для нового чата — по языку клиента Telegram.
+func (b *Bot) detectLang(upd tgbotapi.Update) {
+	m := upd.Message
+	if m == nil || m.IsCommand() {
 		return
 	}
-	chatID := upd.Message.Chat.ID
-	text := upd.Message.Text
-
-	switch text {
-	case "/start":
-		b.Store.Delete(chatID)
-		b.Store.Delete(stKey(chatID))
-		b.History.Delete(chatID)
-		msg := tgbotapi.NewMessage(chatID, "Тілді таңдаңыз / Выберите язык:")
-		msg.ReplyMarkup = keyboard.LangKeyboard()
-		b.API.Send(msg)
-		return
-	case "/help":
-		b.API.Send(tgbotapi.NewMessage(chatID, "Выберите язык, затем раздел."))
-		return
-	case "🇷🇺 Русский":
-		b.Store.Store(chatID, "ru")
-		b.Store.Delete(stKey(chatID))
-		b.History.Delete(chatID)
-		msg := tgbotapi.NewMessage(chatID, "Выберите раздел:")
-		msg.ReplyMarkup = keyboard.Menu(keyboard.MenuRU)
-		b.API.Send(msg)
-		return
-	case "🇰🇿 Қазақша":
-		b.Store.Store(chatID, "kz")
-		b.Store.Delete(stKey(chatID))
-		b.History.Delete(chatID)
-		msg := tgbotapi.NewMessage(chatID, "Бөлімді таңдаңыз:")
-		msg.ReplyMarkup = keyboard.Menu(keyboard.MenuKZ)
-		b.API.Send(msg)
+	s := b.session(m.Chat.ID)
+	if s.Lang != "" && !s.LangAuto {
 		return
 	}
-
-	lang := b.langOf(chatID)
-	forceSmalltalk := b.inSmalltalk(chatID)
-
-	switch text {
-	case "🎓 Образовательные программы":
-		b.sendFromAPI(chatID, "programs", lang)
-		return
-	case "📑 Документы":
-		b.sendFromAPI(chatID, "documents", lang)
-		return
-	case "🎁 Гранты":
-		b.sendFromAPI(chatID, "grants", lang)
-		return
-	case "🏠 Общежитие":
-		b.sendFromAPI(chatID, "dorm", lang)
+	l := lang.Detect(m.Text)
+	if l == "" && s.Lang == "" && m.From != nil {
+		l = lang.FromCode(m.From.LanguageCode)
+	}
+	if l == "" || l == s.Lang {
 		return
+	}
+	b.updateSession(m.Chat.ID, func(s *session.Session) {
+		if s.Lang == "" || s.LangAuto {
+			s.Lang, s.LangAuto = l, true
+		}
+	})
+}
 
-	case "Почему WKATU?", "Преимущества WKATU", "Почему именно WKATU?", "Артықшылықтары WKATU":
-		b.sendFromAPI(chatID, "why-wkatu", lang)
-		return
-	case "Студенческая жизнь", "Развлечения в WKATU", "Клубы и кружки", "Студенттік өмір":
-		b.sendFromAPI(chatID, "campus", lang)
-		return
+func (b *Bot) handleText(c *router.Context) {
+	chatID := c.ChatID
+	text := c.Text
 
-	case "🎓 Білім беру бағдарламалары":
-		b.sendFromAPI(chatID, "programs", lang)
-		return
-	case "📑 Құжаттар":
-		b.sendFromAPI(chatID, "documents", lang)
-		return
-	case "🎁 Гранттар":
-		b.sendFromAPI(chatID, "grants", lang)
-		return
-	case "🏠 Жатақхана":
-		b.sendFromAPI(chatID, "dorm", lang)
+	if slug, ok := b.Resolver.Resolve(text, b.langOf(chatID)); ok {
+		b.section(slug)(c)
 		return
 	}
 
+	lang := b.langOf(chatID)
+	about := profileContext(b.userProfile(c.UserID), lang)
+	forceSmalltalk := b.inSmalltalk(chatID)
+
 	if (isSmalltalk(text) || forceSmalltalk) && b.NLP != nil {
 		b.pushUser(chatID, text)
-		if mini, llm, err := b.NLP.ChatPlus(text, lang, b.getHistory(chatID)); err == nil {
+		if mini, llm, err := b.NLP.ChatPlus(text, lang, b.getHistory(chatID), about); err == nil {
 			if mini != nil && strings.TrimSpace(*mini) != "" {
 				out := sanitizeAnswer(*mini, lang)
 				b.API.Send(tgbotapi.NewMessage(chatID, out))
@@ -234,7 +186,7 @@ func (b *Bot) HandleMessage(upd tgbotapi.Update) {
 		nt := normalizeForClassify(text)
 		if slug, conf, err := b.NLP.Classify(nt); err == nil && slug == "smalltalk" && conf >= classifyThreshold {
 			b.pushUser(chatID, text)
-			if mini, llm, err := b.NLP.ChatPlus(text, lang, b.getHistory(chatID)); err == nil {
+			if mini, llm, err := b.NLP.ChatPlus(text, lang, b.getHistory(chatID), about); err == nil {
 				if mini != nil && strings.TrimSpace(*mini) != "" {
 					out := sanitizeAnswer(*mini, lang)
 					b.API.Send(tgbotapi.NewMessage(chatID, out))
@@ -254,7 +206,7 @@ func (b *Bot) HandleMessage(upd tgbotapi.Update) {
 		}
 
 		b.pushUser(chatID, text)
-		if mini, llm, err := b.NLP.ChatPlus(text, lang, b.getHistory(chatID)); err == nil {
+		if mini, llm, err := b.NLP.ChatPlus(text, lang, b.getHistory(chatID), about); err == nil {
 			if mini != nil && strings.TrimSpace(*mini) != "" {
 				out := sanitizeAnswer(*mini, lang)
 				b.API.Send(tgbotapi.NewMessage(chatID, out))
@@ -279,10 +231,49 @@ func (b *Bot) HandleMessage(upd tgbotapi.Update) {
 }
 
 func (b *Bot) sendFromAPI(chatID int64, slug, lang string) {
+	b.API.Send(tgbotapi.NewMessage(chatID, b.contentText(chatID, slug, lang)))
+}
+
+// contentText — раздел контента с подсказкой под профиль абитуриента.
+func (b *Bot) contentText(chatID int64, slug, lang string) string {
 	c, err := b.APICl.Get(slug, lang)
 	if err != nil {
-		b.API.Send(tgbotapi.NewMessage(chatID, "Данные скоро обновим."))
+		return "Данные скоро обновим."
+	}
+	text := c.Title + "\n\n" + c.Body
+	// в личном чате ID чата — это ID пользователя; в группе профиля нет
+	if hint := b.profileHint(slug, lang, b.userProfile(chatID)); hint != "" {
+		text += "\n\n" + hint
+	}
+	return text
+}
+
+// ReplyMaintenance и ReplyRateLimited — ответы для middleware, которые
+// не пропускают апдейт до роутера.
+func (b *Bot) ReplyMaintenance(upd tgbotapi.Update) {
+	b.replyService(upd,
+		"Бот на техническом обслуживании, попробуйте чуть позже 🙏",
+		"Бот техникалық қызмет көрсетуде, сәл кейінірек көріңіз 🙏")
+}
+
+func (b *Bot) ReplyRateLimited(upd tgbotapi.Update) {
+	b.replyService(upd,
+		"Слишком много сообщений подряд. Подождите немного, пожалуйста.",
+		"Хабарламалар тым көп. Сәл күте тұрыңыз.")
+}
+
+func (b *Bot) replyService(upd tgbotapi.Update, ru, kz string) {
+	if upd.CallbackQuery != nil {
+		b.API.Request(tgbotapi.NewCallback(upd.CallbackQuery.ID, ru))
 		return
 	}
-	b.API.Send(tgbotapi.NewMessage(chatID, c.Title+"\n\n"+c.Body))
+	chat := upd.FromChat()
+	if chat == nil {
+		return
+	}
+	text := ru
+	if b.langOf(chat.ID) == "kz" {
+		text = kz
+	}
+	b.API.Send(tgbotapi.NewMessage(chat.ID, text))
 }
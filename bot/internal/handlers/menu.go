@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	keyboard "telegramBot/bot/internal/keybord"
+	"telegramBot/bot/internal/menu"
+	"telegramBot/bot/internal/router"
+	"telegramBot/bot/internal/session"
+)
+
+const menuTimeout = 3 * time.Second
+
+// navStackLimit ограничивает глубину «Назад»: глубже меню всё равно не бывает.
+const navStackLimit = 16
+
+// Callback data навигации: nav:<code узла>, nav:back, nav:home;
+// pg:<code>:<страница> листает кнопки узла, noop — кнопка-подпись.
+const (
+	navPrefix  = "nav:"
+	navBack    = "back"
+	navHome    = "home"
+	pagePrefix = "pg:"
+	noopData   = "noop"
+)
+
+var (
+	backLabel = map[string]string{"ru": "⬅️ Назад", "kz": "⬅️ Артқа"}
+	homeLabel = map[string]string{"ru": "🏠 В начало", "kz": "🏠 Басына"}
+	staleText = map[string]string{"ru": "Меню устарело — вот актуальное.", "kz": "Мәзір ескірген — міне, жаңасы."}
+)
+
+// staticMenu повторяет прежнее зашитое меню; коды и slug совпадают с сидом
+// узлы_меню в init.sql.
+var staticMenu = func() menu.Static {
+	m := menu.Static{"main": {
+		"ru": {Title: "Выберите раздел:"},
+		"kz": {Title: "Бөлімді таңдаңыз:"},
+	}}
+	codes := []string{"programs", "documents", "grants", "dorm"}
+	for i, code := range codes {
+		slug := code
+		m[code] = map[string]menu.Screen{
+			"ru": {Title: keyboard.MenuRU[i], Slug: &slug},
+			"kz": {Title: keyboard.MenuKZ[i], Slug: &slug},
+		}
+		ru, kz := m["main"]["ru"], m["main"]["kz"]
+		ru.Buttons = append(ru.Buttons, menu.Button{Text: keyboard.MenuRU[i], Next: code})
+		kz.Buttons = append(kz.Buttons, menu.Button{Text: keyboard.MenuKZ[i], Next: code})
+		m["main"]["ru"], m["main"]["kz"] = ru, kz
+	}
+	return m
+}()
+
+// screen берёт экран из Menu, а если база недоступна или меню для tenant
+// в ней не заведено (нет корневого узла) — из staticMenu.
+func (b *Bot) screen(code, lang string) (*menu.Screen, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), menuTimeout)
+	defer cancel()
+	scr, err := b.Menu.GetScreen(ctx, code, lang)
+	switch {
+	case err == nil:
+		return scr, nil
+	case !errors.Is(err, menu.ErrNotFound):
+		log.Printf("menu %s/%s: %v", code, lang, err)
+	case code != b.root():
+		// узла нет, но само меню в базе есть — кнопка устарела
+		if _, rerr := b.Menu.GetScreen(ctx, b.root(), lang); !errors.Is(rerr, menu.ErrNotFound) {
+			return nil, err
+		}
+	}
+	if code == b.root() {
+		scr, err = staticMenu.GetScreen(ctx, "main", lang)
+		if scr != nil {
+			scr.Code = code
+		}
+		return scr, err
+	}
+	return staticMenu.GetScreen(ctx, code, lang)
+}
+
+func (b *Bot) root() string {
+	if b.MenuRoot != "" {
+		return b.MenuRoot
+	}
+	return "main"
+}
+
+// showRoot отправляет корневой экран новым сообщением и сбрасывает стек навигации.
+func (b *Bot) showRoot(chatID int64, lang string) {
+	b.updateSession(chatID, func(s *session.Session) {
+		s.Node = b.root()
+		s.NavStack = nil
+	})
+	b.render(chatID, 0, b.root(), lang, 0)
+}
+
+// render показывает узел: заголовок (или контент по slug) и страницу page
+// его кнопок. msgID != 0 — отредактировать это сообщение вместо отправки нового.
+func (b *Bot) render(chatID int64, msgID int, code, lang string, page int) {
+	scr, err := b.screen(code, lang)
+	if err != nil {
+		log.Printf("menu %q: %v", code, err)
+		b.reply(chatID, msgID, "Данные скоро обновим.", nil)
+		return
+	}
+	text := scr.Title
+	if scr.Slug != nil && *scr.Slug != "" {
+		text = b.contentText(chatID, *scr.Slug, lang)
+	}
+	items := make([]keyboard.Item, 0, len(scr.Buttons))
+	for _, bt := range scr.Buttons {
+		items = append(items, keyboard.Item{Text: bt.Text, Data: navPrefix + bt.Next})
+	}
+	var footer []keyboard.Item
+	if code != b.root() {
+		footer = []keyboard.Item{
+			{Text: backLabel[lang], Data: navPrefix + navBack},
+			{Text: homeLabel[lang], Data: navPrefix + navHome},
+		}
+	}
+	layout := keyboard.Layout{Columns: scr.Columns, PageSize: scr.PageSize}
+	pageData := func(p int) string { return pagePrefix + code + ":" + strconv.Itoa(p) }
+	kb := keyboard.Inline(items, layout, page, pageData, noopData, footer...)
+	if len(kb.InlineKeyboard) == 0 {
+		b.reply(chatID, msgID, text, nil)
+		return
+	}
+	b.reply(chatID, msgID, text, &kb)
+}
+
+func (b *Bot) reply(chatID int64, msgID int, text string, kb *tgbotapi.InlineKeyboardMarkup) {
+	if msgID == 0 {
+		msg := tgbotapi.NewMessage(chatID, text)
+		if kb != nil {
+			msg.ReplyMarkup = *kb
+		}
+		b.API.Send(msg)
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(chatID, msgID, text)
+	edit.ReplyMarkup = kb
+	b.API.Send(edit)
+}
+
+// goTo переходит на узел code (или назад / в начало) и возвращает узел,
+// который теперь нужно показать.
+func (b *Bot) goTo(chatID int64, code string) string {
+	var to string
+	b.updateSession(chatID, func(s *session.Session) {
+		cur := s.Node
+		if cur == "" {
+			cur = b.root()
+		}
+		switch code {
+		case navHome:
+			s.NavStack = nil
+			to = b.root()
+		case navBack:
+			to = b.root()
+			if n := len(s.NavStack); n > 0 {
+				to = s.NavStack[n-1]
+				s.NavStack = s.NavStack[:n-1]
+			}
+		default:
+			to = code
+			if cur != code {
+				s.NavStack = append(s.NavStack, cur)
+				if len(s.NavStack) > navStackLimit {
+					s.NavStack = s.NavStack[len(s.NavStack)-navStackLimit:]
+				}
+			}
+		}
+		s.Node = to
+		s.Smalltalk = 0
+	})
+	return to
+}
+
+// handleNav — нажатие inline-кнопки меню: экран меняется в том же сообщении.
+func (b *Bot) handleNav(c *router.Context) {
+	cq := c.Update.CallbackQuery
+	b.API.Request(tgbotapi.NewCallback(cq.ID, ""))
+	lang := b.langOf(c.ChatID)
+	code := c.Args
+	if code != navBack && code != navHome {
+		// кнопка из старого сообщения может вести на удалённый узел
+		if _, err := b.screen(code, lang); err != nil {
+			log.Printf("menu %q: %v", code, err)
+			code = navHome
+		}
+	}
+	to := b.goTo(c.ChatID, code)
+	msgID := 0
+	if cq.Message != nil {
+		msgID = cq.Message.MessageID
+	}
+	b.render(c.ChatID, msgID, to, lang, 0)
+}
+
+// handlePage листает кнопки узла в том же сообщении; стек навигации не меняется.
+func (b *Bot) handlePage(c *router.Context) {
+	cq := c.Update.CallbackQuery
+	b.API.Request(tgbotapi.NewCallback(cq.ID, ""))
+	i := strings.LastIndexByte(c.Args, ':')
+	if i < 0 || cq.Message == nil {
+		return
+	}
+	page, err := strconv.Atoi(c.Args[i+1:])
+	if err != nil {
+		return
+	}
+	b.render(c.ChatID, cq.Message.MessageID, c.Args[:i], b.langOf(c.ChatID), page)
+}
+
+// staleCallback отвечает на кнопку, которую бот больше не знает (сообщение
+// из старой версии), и показывает актуальное меню на её месте.
+func (b *Bot) staleCallback(c *router.Context) {
+	cq := c.Update.CallbackQuery
+	log.Printf("stale callback %q in chat %d", c.Data, c.ChatID)
+	lang := b.langOf(c.ChatID)
+	b.API.Request(tgbotapi.NewCallback(cq.ID, staleText[lang]))
+	to := b.goTo(c.ChatID, navHome)
+	msgID := 0
+	if cq.Message != nil {
+		msgID = cq.Message.MessageID
+	}
+	b.render(c.ChatID, msgID, to, lang, 0)
+}
+
+func (b *Bot) answerNoop(c *router.Context) {
+	b.API.Request(tgbotapi.NewCallback(c.Update.CallbackQuery.ID, ""))
+}
+
+// intercept: сначала незаконченный диалог, потом кнопки меню — они важнее
+// зашитых подписей разделов, чтобы меню из базы можно было перестраивать.
+func (b *Bot) intercept(c *router.Context) bool {
+	return b.stepFlow(c) || b.navigate(c)
+}
+
+// navigate обрабатывает подписи кнопок меню, отправленные текстом (старая
+// reply-клавиатура или ввод руками): ищет кнопку на текущем экране или на
+// корневом и показывает её узел новым сообщением.
+func (b *Bot) navigate(c *router.Context) bool {
+	s := b.session(c.ChatID)
+	lang := b.langFrom(s)
+
+	codes := []string{b.root()}
+	if s.Node != "" && s.Node != b.root() {
+		codes = append([]string{s.Node}, codes...)
+	}
+	for _, code := range codes {
+		scr, err := b.screen(code, lang)
+		if err != nil {
+			continue
+		}
+		for _, bt := range scr.Buttons {
+			if bt.Text == c.Text {
+				b.render(c.ChatID, 0, b.goTo(c.ChatID, bt.Next), lang, 0)
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBot/bot/internal/router"
+)
+
+// sessionData отдаёт в privacy.Registry сессию личного чата пользователя:
+// язык, профиль и историю переписки, которую бот передаёт в NLP.
+type sessionData struct{ b *Bot }
+
+func (sessionData) Name() string { return "sessions" }
+
+func (d sessionData) Export(ctx context.Context, userID int64) (any, error) {
+	s, err := d.b.Sessions.Load(ctx, userID)
+	if err != nil || s.LastActive.IsZero() {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (d sessionData) Erase(ctx context.Context, userID int64) error {
+	return d.b.Sessions.Delete(ctx, userID)
+}
+
+// profileData отдаёт в privacy.Registry профиль абитуриента.
+type profileData struct{ b *Bot }
+
+func (profileData) Name() string { return "profile" }
+
+func (d profileData) Export(ctx context.Context, userID int64) (any, error) {
+	p, err := d.b.Profiles.Load(ctx, userID)
+	if err != nil || p.Empty() {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (d profileData) Erase(ctx context.Context, userID int64) error {
+	return d.b.Profiles.Erase(ctx, userID)
+}
+
+const privacyTimeout = 10 * time.Second
+
+// privateOnly не даёт выгружать и стирать личные данные в группе.
+func (b *Bot) privateOnly(c *router.Context) bool {
+	if m := c.Update.Message; m != nil && !m.Chat.IsPrivate() {
+		text := "Эта команда работает только в личном чате с ботом."
+		if b.langOf(c.ChatID) == "kz" {
+			text = "Бұл команда тек ботпен жеке чатта жұмыс істейді."
+		}
+		b.API.Send(tgbotapi.NewMessage(c.ChatID, text))
+		return false
+	}
+	return true
+}
+
+func (b *Bot) handleMyData(c *router.Context) {
+	if !b.privateOnly(c) {
+		return
+	}
+	kz := b.langOf(c.ChatID) == "kz"
+	ctx, cancel := context.WithTimeout(context.Background(), privacyTimeout)
+	defer cancel()
+
+	data, err := b.Privacy.Export(ctx, c.UserID)
+	if err != nil {
+		log.Printf("mydata %d: %v", c.UserID, err)
+		text := "Не удалось собрать данные, попробуйте позже."
+		if kz {
+			text = "Деректерді жинау мүмкін болмады, кейінірек көріңіз."
+		}
+		b.API.Send(tgbotapi.NewMessage(c.ChatID, text))
+		return
+	}
+	body, _ := json.MarshalIndent(struct {
+		UserID     int64          `json:"user_id"`
+		ExportedAt time.Time      `json:"exported_at"`
+		Data       map[string]any `json:"data"`
+	}{c.UserID, time.Now().UTC(), data}, "", "  ")
+
+	doc := tgbotapi.NewDocument(c.ChatID, tgbotapi.FileBytes{Name: "mydata.json", Bytes: body})
+	doc.Caption = "Всё, что бот хранит о вас. Удалить — /forget."
+	if kz {
+		doc.Caption = "Бот сіз туралы сақтайтын барлық деректер. Өшіру — /forget."
+	}
+	b.API.Send(doc)
+}
+
+func (b *Bot) handleForget(c *router.Context) {
+	if !b.privateOnly(c) {
+		return
+	}
+	msg := tgbotapi.NewMessage(c.ChatID, "Удалить язык, профиль и историю переписки? Это нельзя отменить.")
+	yes, no := "Да, удалить", "Нет"
+	if b.langOf(c.ChatID) == "kz" {
+		msg.Text = "Тілді, профильді және хат алмасу тарихын өшіру керек пе? Мұны қайтару мүмкін емес."
+		yes, no = "Иә, өшіру", "Жоқ"
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(yes, "forget:yes"),
+		tgbotapi.NewInlineKeyboardButtonData(no, "forget:no"),
+	))
+	b.API.Send(msg)
+}
+
+func (b *Bot) confirmForget(c *router.Context) {
+	cq := c.Update.CallbackQuery
+	b.API.Request(tgbotapi.NewCallback(cq.ID, ""))
+	kz := b.langOf(c.ChatID) == "kz"
+
+	text := "Хорошо, ничего не удаляем."
+	if kz {
+		text = "Жарайды, ештеңе өшірмейміз."
+	}
+	if c.Args == "yes" {
+		ctx, cancel := context.WithTimeout(context.Background(), privacyTimeout)
+		defer cancel()
+		if err := b.Privacy.Erase(ctx, c.UserID); err != nil {
+			log.Printf("forget %d: %v", c.UserID, err)
+			text = "Удалить удалось не всё, попробуйте ещё раз позже."
+			if kz {
+				text = "Барлығын өшіру мүмкін болмады, кейінірек қайталап көріңіз."
+			}
+		} else {
+			text = "Готово: ваши данные удалены. Чтобы начать заново — /start."
+			if kz {
+				text = "Дайын: деректеріңіз өшірілді. Қайта бастау үшін — /start."
+			}
+		}
+	}
+	if cq.Message == nil {
+		b.API.Send(tgbotapi.NewMessage(c.ChatID, text))
+		return
+	}
+	b.API.Send(tgbotapi.NewEditMessageText(c.ChatID, cq.Message.MessageID, text))
+}
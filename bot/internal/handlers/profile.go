@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBot/bot/internal/fsm"
+	"telegramBot/bot/internal/profile"
+	"telegramBot/bot/internal/router"
+	"telegramBot/bot/internal/session"
+)
+
+// Профиль абитуриента хранится в Profiles по ID пользователя. Заполнять
+// его можно только в личке: там ID чата совпадает с ID пользователя.
+
+var regions = []fsm.Choice{
+	{Value: "west-kz", Labels: map[string]string{"ru": "Западно-Казахстанская обл.", "kz": "Батыс Қазақстан обл."}},
+	{Value: "atyrau", Labels: map[string]string{"ru": "Атырауская обл.", "kz": "Атырау обл."}},
+	{Value: "aktobe", Labels: map[string]string{"ru": "Актюбинская обл.", "kz": "Ақтөбе обл."}},
+	{Value: "mangystau", Labels: map[string]string{"ru": "Мангистауская обл.", "kz": "Маңғыстау обл."}},
+	{Value: "south", Labels: map[string]string{"ru": "Юг Казахстана", "kz": "Оңтүстік Қазақстан"}},
+	{Value: "other", Labels: map[string]string{"ru": "Другой регион", "kz": "Басқа өңір"}},
+}
+
+func regionLabel(value, lang string) string {
+	for _, r := range regions {
+		if r.Value == value {
+			return r.Label(lang)
+		}
+	}
+	return value
+}
+
+func (b *Bot) profileFlow() *fsm.Flow {
+	next := func(s string) func(map[string]string) string {
+		return func(map[string]string) string { return s }
+	}
+	return &fsm.Flow{
+		Name:  "profile",
+		Start: "program",
+		States: map[string]fsm.State{
+			"program": {
+				Prompt: map[string]string{
+					"ru": "Заполним профиль — так ответы будут точнее. Любой вопрос можно пропустить.\n\nКакая программа вас интересует?",
+					"kz": "Профильді толтырайық — жауаптар нақтырақ болады. Кез келген сұрақты өткізіп жіберуге болады.\n\nСізді қай бағдарлама қызықтырады?",
+				},
+				Input:    fsm.InputChoice,
+				Choices:  programs,
+				Optional: true,
+				Next:     next("region"),
+			},
+			"region": {
+				Prompt:   map[string]string{"ru": "Из какого вы региона?", "kz": "Қай өңірденсіз?"},
+				Input:    fsm.InputChoice,
+				Choices:  regions,
+				Optional: true,
+				Next:     next("ent"),
+			},
+			"ent": {
+				Prompt: map[string]string{
+					"ru": "Сколько баллов ЕНТ у вас (или сколько ожидаете)?",
+					"kz": "ҰБТ балыңыз қанша (немесе қанша күтесіз)?",
+				},
+				Input:    fsm.InputNumber,
+				Min:      0,
+				Max:      140,
+				Optional: true,
+				Next:     next("contact"),
+			},
+			"contact": {
+				Prompt: map[string]string{
+					"ru": "Как с вами связаться приёмной комиссии? Телефон или e-mail.",
+					"kz": "Қабылдау комиссиясы сізбен қалай байланыса алады? Телефон немесе e-mail.",
+				},
+				Input:    fsm.InputText,
+				MaxLen:   100,
+				Optional: true,
+			},
+		},
+		Finish: b.saveProfile,
+	}
+}
+
+// saveProfile дописывает ответы в профиль; пропущенный вопрос не стирает
+// то, что уже было в профиле.
+func (b *Bot) saveProfile(lang string, data map[string]string, s *session.Session) string {
+	ctx, cancel := sessionCtx()
+	defer cancel()
+	p, err := b.Profiles.Load(ctx, s.ChatID)
+	if err == nil && p.Empty() && s.LegacyProfile != nil {
+		p = *s.LegacyProfile
+	}
+	if err == nil {
+		if v := data["program"]; v != "" {
+			p.Program = v
+		}
+		if v := data["region"]; v != "" {
+			p.Region = v
+		}
+		if ent, aerr := strconv.Atoi(data["ent"]); aerr == nil {
+			p.ENT = ent
+		}
+		if v := data["contact"]; v != "" {
+			p.Contact = v
+		}
+		err = b.Profiles.Save(ctx, s.ChatID, p)
+	}
+	if err != nil {
+		log.Printf("profile save %d: %v", s.ChatID, err)
+		if lang == "kz" {
+			return "Профильді сақтау мүмкін болмады, кейінірек қайталап көріңіз."
+		}
+		return "Не удалось сохранить профиль, попробуйте ещё раз позже."
+	}
+	s.LegacyProfile = nil
+	return profileSummary(p, lang)
+}
+
+// userProfile — профиль пользователя. Профиль, который прежние версии бота
+// держали в сессии, при этом переносится в Profiles.
+func (b *Bot) userProfile(userID int64) profile.Profile {
+	ctx, cancel := sessionCtx()
+	defer cancel()
+	p, err := b.Profiles.Load(ctx, userID)
+	if err != nil {
+		log.Printf("profile load %d: %v", userID, err)
+		return profile.Profile{}
+	}
+	if !p.Empty() {
+		return p
+	}
+	legacy := b.session(userID).LegacyProfile
+	if legacy == nil {
+		return p
+	}
+	if !legacy.Empty() {
+		if err := b.Profiles.Save(ctx, userID, *legacy); err != nil {
+			log.Printf("profile save %d: %v", userID, err)
+			return *legacy
+		}
+	}
+	b.updateSession(userID, func(s *session.Session) { s.LegacyProfile = nil })
+	return *legacy
+}
+
+func (b *Bot) handleProfile(c *router.Context) {
+	if m := c.Update.Message; m != nil && !m.Chat.IsPrivate() {
+		text := "Профиль можно заполнить в личном чате с ботом."
+		if b.langOf(c.ChatID) == "kz" {
+			text = "Профильді ботпен жеке чатта толтыруға болады."
+		}
+		b.API.Send(tgbotapi.NewMessage(c.ChatID, text))
+		return
+	}
+	b.startFlow("profile")(c)
+}
+
+func profileSummary(p profile.Profile, lang string) string {
+	kz := lang == "kz"
+	var rows []string
+	add := func(ru, kzl, v string) {
+		if v == "" {
+			return
+		}
+		if kz {
+			rows = append(rows, "• "+kzl+": "+v)
+		} else {
+			rows = append(rows, "• "+ru+": "+v)
+		}
+	}
+	add("Программа", "Бағдарлама", programLabel(p.Program, lang))
+	add("Регион", "Өңір", regionLabel(p.Region, lang))
+	if p.ENT > 0 {
+		add("Балл ЕНТ", "ҰБТ балы", strconv.Itoa(p.ENT))
+	}
+	add("Контакт", "Байланыс", p.Contact)
+
+	if len(rows) == 0 {
+		if kz {
+			return "Профиль бос. Толтыру — /profile."
+		}
+		return "Профиль пуст. Заполнить — /profile."
+	}
+	if kz {
+		return "Профиль сақталды:\n" + strings.Join(rows, "\n") + "\n\nӨзгерту — /profile."
+	}
+	return "Профиль сохранён:\n" + strings.Join(rows, "\n") + "\n\nИзменить — /profile."
+}
+
+// regionHintSlug — контент с советом для абитуриентов из региона, например
+// hint-region-south про гранты «Серпін». Нет такого контента — нет совета.
+func regionHintSlug(region string) string { return "hint-region-" + region }
+
+// profileHint дополняет раздел контента советом под профиль абитуриента.
+func (b *Bot) profileHint(slug, lang string, p profile.Profile) string {
+	if slug != "grants" {
+		return ""
+	}
+	kz := lang == "kz"
+	if p.ENT == 0 && p.Region == "" {
+		if kz {
+			return "Өзіңізге қандай гранттар сай келетінін білу үшін /profile толтырыңыз."
+		}
+		return "Заполните /profile — подскажем, какие гранты подходят именно вам."
+	}
+	grantThreshold := b.GrantThreshold
+	var hints []string
+	switch {
+	case p.ENT == 0:
+	case p.ENT < grantThreshold && kz:
+		hints = append(hints, fmt.Sprintf("Сіздің ҰБТ балыңыз (%d) гранттық конкурс шегінен (%d) төмен — ақылы оқуды немесе ҰБТ-ны қайта тапсыруды қарастырыңыз.", p.ENT, grantThreshold))
+	case p.ENT < grantThreshold:
+		hints = append(hints, fmt.Sprintf("Ваш балл ЕНТ (%d) ниже порога конкурса на грант (%d) — рассмотрите платное обучение или пересдачу ЕНТ.", p.ENT, grantThreshold))
+	case kz:
+		hints = append(hints, fmt.Sprintf("Сіздің ҰБТ балыңыз (%d) гранттық конкурс шегінен (%d) өтеді.", p.ENT, grantThreshold))
+	default:
+		hints = append(hints, fmt.Sprintf("Ваш балл ЕНТ (%d) проходит порог конкурса на грант (%d).", p.ENT, grantThreshold))
+	}
+	if p.Region != "" {
+		if c, err := b.APICl.Get(regionHintSlug(p.Region), lang); err == nil && c.Body != "" {
+			hints = append(hints, c.Body)
+		}
+	}
+	return strings.Join(hints, "\n")
+}
+
+// profileContext — сведения для системного промпта NLP. Контакт туда
+// намеренно не попадает.
+func profileContext(p profile.Profile, lang string) string {
+	var parts []string
+	if p.Program != "" {
+		parts = append(parts, "программа — "+programLabel(p.Program, "ru"))
+	}
+	if p.Region != "" {
+		parts = append(parts, "регион — "+regionLabel(p.Region, "ru"))
+	}
+	if p.ENT > 0 {
+		parts = append(parts, "балл ЕНТ — "+strconv.Itoa(p.ENT))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	if lang == "kz" {
+		return "Талапкер туралы белгілі: " + strings.Join(parts, "; ") + ". Жауап бергенде осыны ескер."
+	}
+	return "Об абитуриенте известно: " + strings.Join(parts, "; ") + ". Учитывай это в ответах."
+}
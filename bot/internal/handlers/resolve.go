@@ -0,0 +1,38 @@
+package handlers
+
+import "telegramBot/bot/internal/resolver"
+
+// synonyms — как пишут разделы руками, по языкам. Остальное приёмная
+// комиссия добавляет в таблицу synonyms без релиза.
+var synonyms = map[string]map[string][]string{
+	"ru": {
+		"programs":  {"программы", "программа", "специальности", "специальность", "направления", "факультеты"},
+		"documents": {"документы", "документ", "какие документы", "список документов"},
+		"grants":    {"гранты", "грант", "бюджет", "бесплатное обучение"},
+		"dorm":      {"общежитие", "общага", "общежития"},
+		"why-wkatu": {"почему wkatu", "почему вы", "преимущества"},
+		"campus":    {"студенческая жизнь", "клубы", "кружки", "секции"},
+	},
+	"kz": {
+		"programs":  {"бағдарламалар", "мамандықтар", "мамандық"},
+		"documents": {"құжаттар", "құжат", "қандай құжаттар"},
+		"grants":    {"гранттар", "грант", "мемлекеттік грант"},
+		"dorm":      {"жатақхана", "жатақханалар"},
+		"why-wkatu": {"неге wkatu", "артықшылықтары"},
+		"campus":    {"студенттік өмір", "клубтар"},
+	},
+}
+
+func newResolver() *resolver.Resolver {
+	r := resolver.New()
+	// подписи кнопок знакомы пользователю на любом языке интерфейса
+	for _, s := range sections {
+		r.Add("", s.slug, s.labels...)
+	}
+	for lang, bySlug := range synonyms {
+		for slug, words := range bySlug {
+			r.Add(lang, slug, words...)
+		}
+	}
+	return r
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	keyboard "telegramBot/bot/internal/keybord"
+	"telegramBot/bot/internal/router"
+	"telegramBot/bot/internal/session"
+)
+
+// sections — кнопки и устаревшие подписи, которые открывают раздел контента.
+var sections = []struct {
+	slug   string
+	labels []string
+}{
+	{"programs", []string{"🎓 Образовательные программы", "🎓 Білім беру бағдарламалары"}},
+	{"documents", []string{"📑 Документы", "📑 Құжаттар"}},
+	{"grants", []string{"🎁 Гранты", "🎁 Гранттар"}},
+	{"dorm", []string{"🏠 Общежитие", "🏠 Жатақхана"}},
+	{"why-wkatu", []string{"Почему WKATU?", "Преимущества WKATU", "Почему именно WKATU?", "Артықшылықтары WKATU"}},
+	{"campus", []string{"Студенческая жизнь", "Развлечения в WKATU", "Клубы и кружки", "Студенттік өмір"}},
+}
+
+func (b *Bot) routes() *router.Router {
+	r := router.New()
+	r.Command("start", "Запустить бота", b.handleStart)
+	r.Command("help", "Помощь", b.handleHelp)
+	r.Command("lang", "Сменить язык / Тілді ауыстыру", b.handleLang)
+	r.Command("grant", "Проверить шансы на грант", b.startFlow("grant"))
+	r.Command("profile", "Мой профиль абитуриента", b.handleProfile)
+	r.Command("cancel", "Прервать текущий диалог", b.handleCancel)
+	r.Command("mydata", "Какие данные бот хранит обо мне", b.handleMyData)
+	r.Command("forget", "Удалить мои данные", b.handleForget)
+	r.Callback("forget:", b.confirmForget)
+	r.Callback("lang:", b.pickLang)
+	r.Callback(navPrefix, b.handleNav)
+	r.Callback(pagePrefix, b.handlePage)
+	r.Callback(noopData, b.answerNoop)
+	r.CallbackFallback(b.staleCallback)
+	r.Intercept(b.intercept)
+
+	r.Text(b.chooseLang("ru"), "🇷🇺 Русский")
+	r.Text(b.chooseLang("kz"), "🇰🇿 Қазақша")
+	for _, s := range sections {
+		r.Text(b.section(s.slug), s.labels...)
+	}
+
+	r.Fallback(b.handleText)
+	return r
+}
+
+func (b *Bot) handleStart(c *router.Context) {
+	if c.Args != "" && b.startLink(c) {
+		return
+	}
+	b.resetSession(c.ChatID, "")
+	msg := tgbotapi.NewMessage(c.ChatID, "Тілді таңдаңыз / Выберите язык:")
+	msg.ReplyMarkup = keyboard.LangKeyboard()
+	b.API.Send(msg)
+}
+
+func (b *Bot) handleHelp(c *router.Context) {
+	var sb strings.Builder
+	if b.langOf(c.ChatID) == "kz" {
+		sb.WriteString("Тілді, содан кейін бөлімді таңдаңыз.\n\nКомандалар:")
+	} else {
+		sb.WriteString("Выберите язык, затем раздел.\n\nКоманды:")
+	}
+	for _, cmd := range b.Router.Commands() {
+		sb.WriteString("\n/" + cmd.Command + " — " + cmd.Description)
+	}
+	b.API.Send(tgbotapi.NewMessage(c.ChatID, sb.String()))
+}
+
+// handleLang сразу меняет язык (/lang kz) или показывает клавиатуру выбора;
+// в отличие от /start история разговора и диалоги не сбрасываются.
+func (b *Bot) handleLang(c *router.Context) {
+	switch l := strings.ToLower(c.Args); l {
+	case "ru", "kz":
+		b.chooseLang(l)(c)
+	case "kk", "қаз", "каз":
+		b.chooseLang("kz")(c)
+	case "рус":
+		b.chooseLang("ru")(c)
+	default:
+		msg := tgbotapi.NewMessage(c.ChatID, "Тілді таңдаңыз / Выберите язык:")
+		msg.ReplyMarkup = keyboard.LangKeyboard()
+		b.API.Send(msg)
+	}
+}
+
+func (b *Bot) chooseLang(lang string) router.HandlerFunc {
+	return func(c *router.Context) {
+		b.setLang(c.ChatID, lang)
+		b.showRoot(c.ChatID, lang)
+	}
+}
+
+// pickLang — выбор языка inline-кнопкой: то же сообщение превращается
+// в главное меню.
+func (b *Bot) pickLang(c *router.Context) {
+	cq := c.Update.CallbackQuery
+	b.API.Request(tgbotapi.NewCallback(cq.ID, ""))
+	lang := c.Args
+	if lang != "ru" && lang != "kz" {
+		return
+	}
+	b.setLang(c.ChatID, lang)
+	b.updateSession(c.ChatID, func(s *session.Session) {
+		s.Node = b.root()
+		s.NavStack = nil
+	})
+	msgID := 0
+	if cq.Message != nil {
+		msgID = cq.Message.MessageID
+	}
+	b.render(c.ChatID, msgID, b.root(), lang, 0)
+}
+
+func (b *Bot) section(slug string) router.HandlerFunc {
+	return func(c *router.Context) {
+		b.leaveSmalltalk(c.ChatID)
+		b.sendFromAPI(c.ChatID, slug, b.langOf(c.ChatID))
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"telegramBot/bot/internal/session"
+)
+
+const historyLimit = 8
+
+// sessionTimeout ограничивает каждое обращение к хранилищу сессий: если база
+// тормозит, бот отвечает с настройками по умолчанию, а не висит.
+const sessionTimeout = 3 * time.Second
+
+func sessionCtx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), sessionTimeout)
+}
+
+func (b *Bot) session(chatID int64) session.Session {
+	ctx, cancel := sessionCtx()
+	defer cancel()
+	s, err := b.Sessions.Load(ctx, chatID)
+	if err != nil {
+		log.Printf("session load %d: %v", chatID, err)
+		return session.Session{ChatID: chatID}
+	}
+	return s
+}
+
+func (b *Bot) updateSession(chatID int64, fn func(s *session.Session)) session.Session {
+	ctx, cancel := sessionCtx()
+	defer cancel()
+	s, err := b.Sessions.Update(ctx, chatID, func(s *session.Session) error {
+		fn(s)
+		return nil
+	})
+	if err != nil {
+		log.Printf("session update %d: %v", chatID, err)
+	}
+	return s
+}
+
+func (b *Bot) langOf(chatID int64) string {
+	return b.langFrom(b.session(chatID))
+}
+
+func (b *Bot) langFrom(s session.Session) string {
+	if l := s.Lang; l == "ru" || l == "kz" {
+		return l
+	}
+	if b.DefaultLang == "kz" {
+		return "kz"
+	}
+	return "ru"
+}
+
+// setLang запоминает язык, выбранный пользователем явно; автоопределение
+// его больше не меняет.
+func (b *Bot) setLang(chatID int64, lang string) {
+	b.updateSession(chatID, func(s *session.Session) {
+		s.Lang = lang
+		s.LangAuto = false
+	})
+}
+
+// resetSession забывает язык и ход разговора (включая незаконченный диалог),
+// но не профиль абитуриента.
+func (b *Bot) resetSession(chatID int64, lang string) {
+	b.updateSession(chatID, func(s *session.Session) {
+		s.Lang = lang
+		s.LangAuto = false
+		s.Node = ""
+		s.NavStack = nil
+		s.Smalltalk = 0
+		s.History = nil
+		s.Flow = nil
+	})
+}
+
+func (b *Bot) getHistory(chatID int64) []map[string]string {
+	var out []map[string]string
+	for _, m := range b.session(chatID).History {
+		out = append(out, map[string]string{"role": m.Role, "content": m.Content})
+	}
+	return out
+}
+
+func (b *Bot) pushUser(chatID int64, text string) {
+	b.updateSession(chatID, func(s *session.Session) { s.PushHistory("user", text, historyLimit) })
+}
+
+func (b *Bot) pushAssistant(chatID int64, text string) {
+	b.updateSession(chatID, func(s *session.Session) { s.PushHistory("assistant", text, historyLimit) })
+}
+
+func (b *Bot) enterSmalltalk(chatID int64) {
+	b.updateSession(chatID, func(s *session.Session) { s.Smalltalk = smalltalkWindow })
+}
+
+func (b *Bot) leaveSmalltalk(chatID int64) {
+	b.updateSession(chatID, func(s *session.Session) { s.Smalltalk = 0 })
+}
+
+func (b *Bot) inSmalltalk(chatID int64) bool {
+	var was bool
+	b.updateSession(chatID, func(s *session.Session) {
+		if s.Smalltalk > 0 {
+			s.Smalltalk--
+			was = true
+		}
+	})
+	return was
+}
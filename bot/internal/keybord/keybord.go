@@ -1,27 +1,119 @@
 package keyboard
 
-import tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+import (
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
 
 var MenuRU = []string{"🎓 Образовательные программы", "📑 Документы", "🎁 Гранты", "🏠 Общежитие"}
 var MenuKZ = []string{"🎓 Білім беру бағдарламалары", "📑 Құжаттар", "🎁 Гранттар", "🏠 Жатақхана"}
 
-func LangKeyboard() tgbotapi.ReplyKeyboardMarkup {
-	kb := tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("🇰🇿 Қазақша"),
-			tgbotapi.NewKeyboardButton("🇷🇺 Русский"),
+// Item — inline-кнопка: подпись и callback data (не длиннее 64 байт).
+type Item struct {
+	Text string
+	Data string
+}
+
+// Layout — раскладка кнопок: Columns в ряд (0 — по одной) и не больше
+// PageSize на странице (0 — DefaultPageSize).
+type Layout struct {
+	Columns  int
+	PageSize int
+}
+
+// DefaultPageSize — сколько кнопок показывать без листания: больше десятка
+// на экране телефона уже не помещается.
+const DefaultPageSize = 10
+
+// MaxColumns — больше кнопок в ряд Telegram сжимает до нечитаемого.
+const MaxColumns = 4
+
+func (l Layout) columns() int {
+	return min(max(l.Columns, 1), MaxColumns)
+}
+
+// pageSize по умолчанию кратен числу колонок, чтобы последний ряд страницы был полным.
+func (l Layout) pageSize() int {
+	if l.PageSize > 0 {
+		return l.PageSize
+	}
+	return DefaultPageSize - DefaultPageSize%l.columns()
+}
+
+// Pages — сколько страниц займут n кнопок.
+func (l Layout) Pages(n int) int {
+	if n == 0 {
+		return 1
+	}
+	return (n + l.pageSize() - 1) / l.pageSize()
+}
+
+func LangKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🇰🇿 Қазақша", "lang:kz"),
+			tgbotapi.NewInlineKeyboardButtonData("🇷🇺 Русский", "lang:ru"),
 		),
 	)
-	kb.ResizeKeyboard = true
-	return kb
 }
 
 func Menu(items []string) tgbotapi.ReplyKeyboardMarkup {
+	return MenuGrid(items, 1)
+}
+
+// MenuGrid — reply-клавиатура по columns кнопок в ряд.
+func MenuGrid(items []string, columns int) tgbotapi.ReplyKeyboardMarkup {
+	columns = Layout{Columns: columns}.columns()
 	var rows [][]tgbotapi.KeyboardButton
-	for _, l := range items {
-		rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(l)))
+	for i := 0; i < len(items); i += columns {
+		var row []tgbotapi.KeyboardButton
+		for _, l := range items[i:min(i+columns, len(items))] {
+			row = append(row, tgbotapi.NewKeyboardButton(l))
+		}
+		rows = append(rows, row)
 	}
 	kb := tgbotapi.NewReplyKeyboard(rows...)
 	kb.ResizeKeyboard = true
 	return kb
 }
+
+// Inline раскладывает страницу page (с нуля) кнопок items по layout. Если
+// страниц несколько, добавляется ряд ◀️ n/m ▶️; pageData даёт callback data
+// для перехода на страницу, noop — для кнопки с номером. footer (например
+// «Назад» и «В начало») — одним рядом в самом низу.
+func Inline(items []Item, layout Layout, page int, pageData func(page int) string, noop string, footer ...Item) tgbotapi.InlineKeyboardMarkup {
+	pages := layout.Pages(len(items))
+	page = min(max(page, 0), pages-1)
+	size := layout.pageSize()
+	shown := items[page*size : min((page+1)*size, len(items))]
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	cols := layout.columns()
+	for i := 0; i < len(shown); i += cols {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, it := range shown[i:min(i+cols, len(shown))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(it.Text, it.Data))
+		}
+		rows = append(rows, row)
+	}
+	if pages > 1 {
+		var row []tgbotapi.InlineKeyboardButton
+		if page > 0 {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("◀️", pageData(page-1)))
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(strconv.Itoa(page+1)+"/"+strconv.Itoa(pages), noop))
+		if page < pages-1 {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("▶️", pageData(page+1)))
+		}
+		rows = append(rows, row)
+	}
+	if len(footer) > 0 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, it := range footer {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(it.Text, it.Data))
+		}
+		rows = append(rows, row)
+	}
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
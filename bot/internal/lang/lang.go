@@ -0,0 +1,73 @@
+package lang
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	RU = "ru"
+	KZ = "kz"
+)
+
+// FromCode переводит User.LanguageCode из Telegram (IETF-тег) в язык бота.
+// Пустая строка — язык бот не поддерживает.
+func FromCode(code string) string {
+	code = strings.ToLower(code)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	switch code {
+	case "kk", "kz":
+		return KZ
+	case "ru", "uk", "be":
+		return RU
+	}
+	return ""
+}
+
+// kzLetters есть только в казахском алфавите. «і» сюда не входит: она
+// есть в украинском и белорусском, и одна такая буква не значит казахский.
+const kzLetters = "әғқңөұүһ"
+
+// kzWords — частые казахские слова, которые пишут и без казахских букв
+// (на русской раскладке).
+var kzWords = map[string]bool{
+	"рахмет": true, "керек": true, "туралы": true, "бойынша": true,
+	"сен": true, "мен": true, "сиз": true,
+	"калай": true, "кандай": true, "канша": true, "кайда": true,
+	"жок": true, "жане": true, "салем": true, "иа": true,
+	"жатахана": true, "оку": true, "тусу": true, "болады": true,
+}
+
+// Detect угадывает язык по тексту: "kz" при казахских буквах или словах,
+// "ru" для кириллицы хотя бы из двух слов без таких признаков, иначе "".
+// Одного слова мало: «грант» или «IT» одинаковы в обоих языках.
+func Detect(text string) string {
+	t := strings.ToLower(text)
+	if strings.ContainsAny(t, kzLetters) {
+		return KZ
+	}
+	cyrillic := 0
+	for _, w := range strings.FieldsFunc(t, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if kzWords[w] {
+			return KZ
+		}
+		if isCyrillic(w) {
+			cyrillic++
+		}
+	}
+	if cyrillic >= 2 {
+		return RU
+	}
+	return ""
+}
+
+func isCyrillic(w string) bool {
+	for _, r := range w {
+		if !unicode.Is(unicode.Cyrillic, r) {
+			return false
+		}
+	}
+	return w != ""
+}
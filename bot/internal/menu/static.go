@@ -0,0 +1,23 @@
+package menu
+
+import "context"
+
+// Static — меню, зашитое в код: узел → язык → экран. Используется, когда
+// у бота нет базы, и как запасной вариант, если база недоступна.
+type Static map[string]map[string]Screen
+
+func (s Static) GetScreen(_ context.Context, code, lang string) (*Screen, error) {
+	byLang, ok := s[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	scr, ok := byLang[lang]
+	if !ok {
+		if scr, ok = byLang["ru"]; !ok {
+			return nil, ErrNotFound
+		}
+	}
+	scr.Code = code
+	scr.Buttons = append([]Button(nil), scr.Buttons...)
+	return &scr, nil
+}
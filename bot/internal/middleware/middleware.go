@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type Handler func(tgbotapi.Update)
+
+type Middleware func(Handler) Handler
+
+// Chain оборачивает h в middleware; первая в списке выполняется первой.
+func Chain(h Handler, mws ...Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Recover не даёт панике в одном хендлере уронить воркер и весь процесс.
+func Recover(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(upd tgbotapi.Update) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("handler panic",
+						append(updateAttrs(upd), "panic", fmt.Sprint(r), "stack", string(debug.Stack()))...)
+				}
+			}()
+			next(upd)
+		}
+	}
+}
+
+func Logging(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(upd tgbotapi.Update) {
+			logger.Info("update", updateAttrs(upd)...)
+			next(upd)
+		}
+	}
+}
+
+// Timing пишет длительность обработки; медленнее slow — с уровнем Warn.
+func Timing(logger *slog.Logger, slow time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(upd tgbotapi.Update) {
+			start := time.Now()
+			next(upd)
+			d := time.Since(start)
+			level := slog.LevelDebug
+			if slow > 0 && d >= slow {
+				level = slog.LevelWarn
+			}
+			logger.Log(context.Background(), level, "handled", append(updateAttrs(upd), "duration_ms", d.Milliseconds())...)
+		}
+	}
+}
+
+// Maintenance отвечает заглушкой вместо обработки, пока enabled() возвращает true.
+func Maintenance(enabled func() bool, reply Handler) Middleware {
+	return func(next Handler) Handler {
+		return func(upd tgbotapi.Update) {
+			if enabled() {
+				reply(upd)
+				return
+			}
+			next(upd)
+		}
+	}
+}
+
+// RateLimit ограничивает пользователя perMinute апдейтами в минуту с запасом burst
+// (token bucket). Первый отклонённый апдейт передаётся в onLimited, остальные
+// молча отбрасываются, пока у пользователя не появится свободный токен.
+// perMinute <= 0 выключает ограничение.
+func RateLimit(perMinute, burst int, onLimited Handler) Middleware {
+	if perMinute <= 0 {
+		return func(next Handler) Handler { return next }
+	}
+	l := &limiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: map[int64]*bucket{},
+	}
+	return func(next Handler) Handler {
+		return func(upd tgbotapi.Update) {
+			u := upd.SentFrom()
+			if u == nil {
+				next(upd)
+				return
+			}
+			ok, warn := l.allow(u.ID, time.Now())
+			if ok {
+				next(upd)
+				return
+			}
+			if warn && onLimited != nil {
+				onLimited(upd)
+			}
+		}
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	warned bool
+}
+
+type limiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[int64]*bucket
+	calls   int
+}
+
+func (l *limiter) allow(userID int64, now time.Time) (ok, warn bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls++
+	if l.calls%1000 == 0 {
+		l.prune(now)
+	}
+
+	b, found := l.buckets[userID]
+	if !found {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[userID] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		b.warned = false
+		return true, false
+	}
+	warn = !b.warned
+	b.warned = true
+	return false, warn
+}
+
+// prune убирает корзины, которые успели наполниться до краёв: они ничем
+// не отличаются от новой и только занимают память.
+func (l *limiter) prune(now time.Time) {
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for id, b := range l.buckets {
+		if now.Sub(b.last) > full {
+			delete(l.buckets, id)
+		}
+	}
+}
+
+func updateAttrs(upd tgbotapi.Update) []any {
+	attrs := []any{"update_id", upd.UpdateID}
+	if c := chatOf(upd); c != 0 {
+		attrs = append(attrs, "chat_id", c)
+	}
+	if u := upd.SentFrom(); u != nil {
+		attrs = append(attrs, "user_id", u.ID)
+	}
+	switch {
+	case upd.Message != nil:
+		attrs = append(attrs, "kind", "message", "text_len", len([]rune(upd.Message.Text)))
+		if upd.Message.IsCommand() {
+			attrs = append(attrs, "command", upd.Message.Command())
+		}
+	case upd.CallbackQuery != nil:
+		attrs = append(attrs, "kind", "callback", "data", upd.CallbackQuery.Data)
+	default:
+		attrs = append(attrs, "kind", "other")
+	}
+	return attrs
+}
+
+func chatOf(upd tgbotapi.Update) int64 {
+	if upd.CallbackQuery != nil && upd.CallbackQuery.Message == nil {
+		return 0
+	}
+	if c := upd.FromChat(); c != nil {
+		return c.ID
+	}
+	return 0
+}
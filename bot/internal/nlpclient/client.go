@@ -11,6 +11,9 @@ import (
 type Client struct {
 	Base string
 	HC   *http.Client
+
+	// Prompts переопределяет системный промпт для языка ("ru", "kz").
+	Prompts map[string]string
 }
 
 type askReq struct {
@@ -43,8 +46,14 @@ type chatPlusResp struct {
 	LLMAnswer        string  `json:"llm_answer"`
 }
 
-func (c *Client) ChatPlus(text, lang string, history []map[string]string) (mini *string, llm string, err error) {
-	sys := map[string]string{"role": "system", "content": systemForLang(lang)}
+// ChatPlus отвечает на реплику с учётом истории. about — дополнительные
+// сведения о собеседнике для системного промпта, может быть пустым.
+func (c *Client) ChatPlus(text, lang string, history []map[string]string, about string) (mini *string, llm string, err error) {
+	system := c.systemForLang(lang)
+	if about != "" {
+		system += "\n\n" + about
+	}
+	sys := map[string]string{"role": "system", "content": system}
 	msgs := append([]map[string]string{sys}, history...)
 	body, _ := json.Marshal(struct {
 		Text    string              `json:"text"`
@@ -91,7 +100,10 @@ func (c *Client) Classify(text string) (slug string, conf float64, err error) {
 	return out.Slug, out.Confidence, nil
 }
 
-func systemForLang(lang string) string {
+func (c *Client) systemForLang(lang string) string {
+	if p := c.Prompts[lang]; p != "" {
+		return p
+	}
 	if lang == "kz" {
 		return "Сен TalapkerBot WKATU көмекшісісің. Пайдаланушы қай тілде жазса, сол тілде қысқа да нақты жауап бер."
 	}
@@ -99,7 +111,7 @@ func systemForLang(lang string) string {
 }
 
 func (c *Client) Chat(text, lang string, history []map[string]string) (string, error) {
-	sys := map[string]string{"role": "system", "content": systemForLang(lang)}
+	sys := map[string]string{"role": "system", "content": c.systemForLang(lang)}
 	msgs := append([]map[string]string{sys}, history...)
 
 	payload := chatReq{
@@ -0,0 +1,193 @@
+package offset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Telegram выбирает следующий update_id случайно, если у бота не было
+// апдейтов неделю, поэтому более старый offset не используется.
+const maxOffsetAge = 7 * 24 * time.Hour
+
+// Store хранит update_id, до которого (включительно) апдейты уже обработаны.
+// Load возвращает -1, если ничего не сохранено: тогда новый и апдейт с
+// update_id 0.
+type Store interface {
+	Load() (int, error)
+	Save(updateID int) error
+}
+
+// FileStore пишет offset в JSON-файл через временный файл и rename,
+// чтобы падение посреди записи не оставило его пустым.
+type FileStore struct {
+	Path string
+}
+
+type fileState struct {
+	UpdateID int       `json:"update_id"`
+	SavedAt  time.Time `json:"saved_at"`
+}
+
+func (f FileStore) Load() (int, error) {
+	raw, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var st fileState
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return 0, err
+	}
+	if time.Since(st.SavedAt) > maxOffsetAge {
+		return -1, nil
+	}
+	return st.UpdateID, nil
+}
+
+func (f FileStore) Save(updateID int) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	raw, _ := json.Marshal(fileState{UpdateID: updateID, SavedAt: time.Now()})
+	tmp := f.Path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.Path)
+}
+
+// Tracker отсекает повторные апдейты и двигает сохранённый offset только
+// до последнего апдейта, перед которым всё уже обработано: при параллельной
+// обработке апдейт 105 может закончиться раньше 104, и после падения 104
+// должен прийти снова, а 105 — нет.
+//
+// Done двигает offset только в памяти; на диск его пишут Flush и Run,
+// чтобы воркеры не ждали файловую запись на каждом апдейте.
+//
+// update_id только растут, поэтому апдейт не новее сохранённого offset
+// считается повтором. Если сервер начал нумерацию заново (перезапущенный
+// fakeapi), offset-файл нужно удалить.
+type Tracker struct {
+	store Store
+
+	saveMu sync.Mutex // Flush-и по очереди, чтобы старый offset не перезаписал новый
+	saved  int
+
+	mu        sync.Mutex
+	committed int
+	maxSeen   int
+	inflight  map[int]struct{}
+	done      map[int]struct{}
+}
+
+func NewTracker(store Store) (*Tracker, error) {
+	id, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Tracker{
+		store:     store,
+		saved:     id,
+		committed: id,
+		maxSeen:   id,
+		inflight:  map[int]struct{}{},
+		done:      map[int]struct{}{},
+	}, nil
+}
+
+// Offset — с какого update_id просить getUpdates.
+func (t *Tracker) Offset() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.committed + 1
+}
+
+// Begin отмечает апдейт как принятый в обработку.
+// false означает, что этот апдейт уже обрабатывается или обработан.
+func (t *Tracker) Begin(updateID int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if updateID <= t.committed {
+		return false
+	}
+	if _, ok := t.inflight[updateID]; ok {
+		return false
+	}
+	if _, ok := t.done[updateID]; ok {
+		return false
+	}
+	t.inflight[updateID] = struct{}{}
+	if updateID > t.maxSeen {
+		t.maxSeen = updateID
+	}
+	return true
+}
+
+// Done отмечает апдейт обработанным и, если можно, двигает offset.
+func (t *Tracker) Done(updateID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.inflight[updateID]; !ok {
+		return
+	}
+	delete(t.inflight, updateID)
+	t.done[updateID] = struct{}{}
+
+	next := t.maxSeen
+	for id := range t.inflight {
+		if id-1 < next {
+			next = id - 1
+		}
+	}
+	if next <= t.committed {
+		return
+	}
+	t.committed = next
+	for id := range t.done {
+		if id <= next {
+			delete(t.done, id)
+		}
+	}
+}
+
+// Flush сохраняет offset, если он сдвинулся с прошлого сохранения.
+func (t *Tracker) Flush() error {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+	t.mu.Lock()
+	id := t.committed
+	t.mu.Unlock()
+	if id == t.saved {
+		return nil
+	}
+	if err := t.store.Save(id); err != nil {
+		return err
+	}
+	t.saved = id
+	return nil
+}
+
+// Run сохраняет offset раз в every, пока не отменён ctx. Последний Flush
+// при остановке делает вызывающий, когда хендлеры уже закончили.
+func (t *Tracker) Run(ctx context.Context, every time.Duration) {
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			if err := t.Flush(); err != nil {
+				log.Printf("offset save: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBot/bot/internal/sender"
+)
+
+var ErrClosed = errors.New("outbox closed")
+
+// Outbox отправляет исходящие сообщения в отдельной горутине. Хендлер не ждёт
+// сетевой запрос к Telegram, а при остановке Close дописывает всё, что осталось
+// в очереди, чтобы ответ пользователю не потерялся.
+type Outbox struct {
+	api   sender.Sender
+	queue chan tgbotapi.Chattable
+	done  chan struct{}
+
+	// quit закрывается в Close и будит Send, ждущий места в очереди.
+	quit    chan struct{}
+	senders sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+}
+
+func New(api sender.Sender, size int) *Outbox {
+	o := &Outbox{
+		api:   api,
+		queue: make(chan tgbotapi.Chattable, size),
+		done:  make(chan struct{}),
+		quit:  make(chan struct{}),
+	}
+	go o.run()
+	return o
+}
+
+// run отправляет сообщения по очереди. Если Telegram ответил 429, чат
+// откладывается до retry_after вместе со всеми его следующими сообщениями
+// (чтобы не нарушить порядок), а остальные чаты отправляются дальше.
+func (o *Outbox) run() {
+	defer close(o.done)
+	held := map[int64]*hold{}
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	queue := o.queue
+	for queue != nil || len(held) > 0 {
+		select {
+		case c, ok := <-queue:
+			if !ok {
+				queue = nil
+				break
+			}
+			chat := chatOf(c)
+			if h := held[chat]; h != nil {
+				h.msgs = append(h.msgs, c)
+				continue
+			}
+			if wait := o.deliver(c); wait > 0 {
+				held[chat] = &hold{until: time.Now().Add(wait), msgs: []tgbotapi.Chattable{c}}
+			}
+		case <-timer.C:
+			o.release(held)
+		}
+		resetTimer(timer, held)
+	}
+}
+
+// hold — сообщения чата, которые нельзя отправлять раньше until.
+type hold struct {
+	until time.Time
+	msgs  []tgbotapi.Chattable
+}
+
+// release отправляет отложенные сообщения чатов, чьё время пришло.
+func (o *Outbox) release(held map[int64]*hold) {
+	now := time.Now()
+	for chat, h := range held {
+		if h.until.After(now) {
+			continue
+		}
+		for len(h.msgs) > 0 {
+			if wait := o.deliver(h.msgs[0]); wait > 0 {
+				h.until = time.Now().Add(wait)
+				break
+			}
+			h.msgs = h.msgs[1:]
+		}
+		if len(h.msgs) == 0 {
+			delete(held, chat)
+		}
+	}
+}
+
+func resetTimer(t *time.Timer, held map[int64]*hold) {
+	t.Stop()
+	var next time.Time
+	for _, h := range held {
+		if next.IsZero() || h.until.Before(next) {
+			next = h.until
+		}
+	}
+	if !next.IsZero() {
+		t.Reset(time.Until(next))
+	}
+}
+
+// deliver отправляет сообщение; при 429 возвращает, сколько подождать
+// перед повтором.
+func (o *Outbox) deliver(c tgbotapi.Chattable) time.Duration {
+	_, err := o.api.Send(c)
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.RetryAfter > 0 {
+		return time.Duration(tgErr.RetryAfter) * time.Second
+	}
+	if err != nil {
+		log.Printf("outbox send: %v", err)
+	}
+	return 0
+}
+
+// chatOf — чат, в который уходит сообщение; 0 — неизвестен, такие
+// сообщения при 429 откладываются вместе.
+func chatOf(c tgbotapi.Chattable) int64 {
+	switch m := c.(type) {
+	case tgbotapi.MessageConfig:
+		return m.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return m.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return m.ChatID
+	case tgbotapi.DocumentConfig:
+		return m.ChatID
+	case tgbotapi.PhotoConfig:
+		return m.ChatID
+	}
+	return 0
+}
+
+// Send ставит сообщение в очередь. Возвращаемый Message пустой: отправка
+// асинхронная, ошибки доставки только логируются.
+func (o *Outbox) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	o.mu.RLock()
+	if o.closed {
+		o.mu.RUnlock()
+		return tgbotapi.Message{}, ErrClosed
+	}
+	// пока идёт постановка в очередь, Close её не закроет
+	o.senders.Add(1)
+	o.mu.RUnlock()
+	defer o.senders.Done()
+
+	select {
+	case o.queue <- c:
+		return tgbotapi.Message{}, nil
+	case <-o.quit:
+		return tgbotapi.Message{}, ErrClosed
+	}
+}
+
+// Request выполняется синхронно: такие вызовы (answerCallbackQuery, setMyCommands)
+// нужны сразу и не относятся к переписке с пользователем.
+func (o *Outbox) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return o.api.Request(c)
+}
+
+// Close перестаёт принимать сообщения и ждёт, пока очередь будет отправлена,
+// но не дольше, чем живёт ctx.
+func (o *Outbox) Close(ctx context.Context) error {
+	o.mu.Lock()
+	first := !o.closed
+	if first {
+		o.closed = true
+		close(o.quit)
+	}
+	o.mu.Unlock()
+	if first {
+		// ждущие места в очереди уже разбужены quit
+		o.senders.Wait()
+		close(o.queue)
+	}
+
+	select {
+	case <-o.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
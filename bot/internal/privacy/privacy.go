@@ -0,0 +1,125 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Holder — хранилище, в котором бот держит данные пользователя:
+// сессии с историей переписки, аналитика и т. п.
+type Holder interface {
+	Name() string
+	// Export возвращает всё, что хранится о пользователе; nil — ничего.
+	Export(ctx context.Context, userID int64) (any, error)
+	Erase(ctx context.Context, userID int64) error
+}
+
+const (
+	ActionExport = "export"
+	ActionErase  = "erase"
+)
+
+// Entry — запись аудита. Самих данных пользователя в ней нет.
+type Entry struct {
+	Bot     string
+	UserID  int64
+	Action  string
+	Holders []string
+	At      time.Time
+}
+
+type Auditor interface {
+	Record(ctx context.Context, e Entry) error
+}
+
+// Registry собирает хранилища бота, чтобы выгружать и стирать данные
+// пользователя во всех сразу.
+type Registry struct {
+	Bot   string
+	Audit Auditor
+
+	holders []Holder
+}
+
+func New(bot string, audit Auditor) *Registry {
+	return &Registry{Bot: bot, Audit: audit}
+}
+
+// Register добавляет хранилище; новое хранилище с данными пользователя
+// обязано регистрироваться здесь, иначе /forget его не затронет.
+func (r *Registry) Register(h Holder) { r.holders = append(r.holders, h) }
+
+// Export — документ для /mydata: хранилище → его данные.
+func (r *Registry) Export(ctx context.Context, userID int64) (map[string]any, error) {
+	out := map[string]any{}
+	names := []string{}
+	for _, h := range r.holders {
+		v, err := h.Export(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", h.Name(), err)
+		}
+		if v != nil {
+			out[h.Name()] = v
+			names = append(names, h.Name())
+		}
+	}
+	r.record(ctx, userID, ActionExport, names)
+	return out, nil
+}
+
+// Erase стирает данные во всех хранилищах, даже если какое-то из них
+// вернуло ошибку; в аудит попадают только успешно очищенные.
+func (r *Registry) Erase(ctx context.Context, userID int64) error {
+	var errs []error
+	names := []string{}
+	for _, h := range r.holders {
+		if err := h.Erase(ctx, userID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.Name(), err))
+			continue
+		}
+		names = append(names, h.Name())
+	}
+	r.record(ctx, userID, ActionErase, names)
+	return errors.Join(errs...)
+}
+
+func (r *Registry) record(ctx context.Context, userID int64, action string, holders []string) {
+	if r.Audit == nil {
+		return
+	}
+	e := Entry{Bot: r.Bot, UserID: userID, Action: action, Holders: holders, At: time.Now()}
+	if err := r.Audit.Record(ctx, e); err != nil {
+		slog.Error("privacy audit", "bot", e.Bot, "user_id", e.UserID, "action", e.Action, "err", err)
+	}
+}
+
+// LogAudit пишет аудит в лог — когда базы нет.
+type LogAudit struct {
+	Logger *slog.Logger
+}
+
+func (a LogAudit) Record(_ context.Context, e Entry) error {
+	l := a.Logger
+	if l == nil {
+		l = slog.Default()
+	}
+	l.Info("privacy audit", "bot", e.Bot, "user_id", e.UserID, "action", e.Action, "holders", e.Holders)
+	return nil
+}
+
+// PostgresAudit пишет аудит в таблицу privacy_audit (см. init.sql).
+type PostgresAudit struct {
+	DB *pgxpool.Pool
+}
+
+func (a PostgresAudit) Record(ctx context.Context, e Entry) error {
+	_, err := a.DB.Exec(ctx,
+		`insert into privacy_audit (bot, user_id, action, holders, created_at) values ($1, $2, $3, $4, $5)`,
+		e.Bot, e.UserID, e.Action, e.Holders, e.At)
+	return err
+}
@@ -0,0 +1,162 @@
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Profile — анкета абитуриента из /profile. Хранится отдельно от сессии
+// чата, по ID пользователя и без срока жизни: сессия может истечь или
+// быть вытеснена, а профиль пропадает только по /forget.
+type Profile struct {
+	Program string `json:"program,omitempty"`
+	Region  string `json:"region,omitempty"`
+	ENT     int    `json:"ent,omitempty"`
+	Contact string `json:"contact,omitempty"`
+}
+
+func (p Profile) Empty() bool { return p == Profile{} }
+
+type Store interface {
+	// Load возвращает профиль пользователя; нет профиля — пустой.
+	Load(ctx context.Context, userID int64) (Profile, error)
+	Save(ctx context.Context, userID int64, p Profile) error
+	Erase(ctx context.Context, userID int64) error
+}
+
+// Memory держит профили в памяти процесса — для тестов и replay.
+type Memory struct {
+	mu       sync.Mutex
+	profiles map[int64]Profile
+}
+
+func NewMemory() *Memory { return &Memory{profiles: map[int64]Profile{}} }
+
+func (m *Memory) Load(_ context.Context, userID int64) (Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.profiles[userID], nil
+}
+
+func (m *Memory) Save(_ context.Context, userID int64, p Profile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profiles[userID] = p
+	return nil
+}
+
+func (m *Memory) Erase(_ context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.profiles, userID)
+	return nil
+}
+
+// File — профили в JSON-файле, когда базы нет: переживают перезапуск.
+// Профили меняются редко, поэтому файл переписывается целиком при каждой
+// записи, через временный файл и rename.
+type File struct {
+	Path string
+
+	mu       sync.Mutex
+	profiles map[int64]Profile
+}
+
+func NewFile(path string) (*File, error) {
+	f := &File{Path: path, profiles: map[int64]Profile{}}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &f.profiles); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) Load(_ context.Context, userID int64) (Profile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.profiles[userID], nil
+}
+
+func (f *File) Save(_ context.Context, userID int64, p Profile) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.profiles[userID] = p
+	return f.write()
+}
+
+func (f *File) Erase(_ context.Context, userID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.profiles[userID]; !ok {
+		return nil
+	}
+	delete(f.profiles, userID)
+	return f.write()
+}
+
+func (f *File) write() error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(f.profiles)
+	if err != nil {
+		return err
+	}
+	tmp := f.Path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.Path)
+}
+
+// Postgres хранит профили в таблице bot_profiles (см. init.sql).
+type Postgres struct {
+	DB  *pgxpool.Pool
+	Bot string
+}
+
+func NewPostgres(db *pgxpool.Pool, bot string) *Postgres {
+	return &Postgres{DB: db, Bot: bot}
+}
+
+func (p *Postgres) Load(ctx context.Context, userID int64) (Profile, error) {
+	var raw []byte
+	err := p.DB.QueryRow(ctx, `select data from bot_profiles where bot=$1 and user_id=$2`, p.Bot, userID).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Profile{}, nil
+	}
+	if err != nil {
+		return Profile{}, err
+	}
+	var pr Profile
+	err = json.Unmarshal(raw, &pr)
+	return pr, err
+}
+
+func (p *Postgres) Save(ctx context.Context, userID int64, pr Profile) error {
+	raw, err := json.Marshal(pr)
+	if err != nil {
+		return err
+	}
+	_, err = p.DB.Exec(ctx, `insert into bot_profiles (bot, user_id, data, updated_at) values ($1, $2, $3, now())
+on conflict (bot, user_id) do update set data = excluded.data, updated_at = now()`, p.Bot, userID, raw)
+	return err
+}
+
+func (p *Postgres) Erase(ctx context.Context, userID int64) error {
+	_, err := p.DB.Exec(ctx, `delete from bot_profiles where bot=$1 and user_id=$2`, p.Bot, userID)
+	return err
+}
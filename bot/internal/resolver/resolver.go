@@ -0,0 +1,191 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxWords — длиннее этого текст считается вопросом, а не названием раздела,
+// и уходит в NLP.
+const maxWords = 4
+
+// fold сводит казахские буквы и ё к русским: на русской раскладке
+// «жатахана» пишут вместо «жатақхана».
+var fold = strings.NewReplacer(
+	"ё", "е", "ә", "а", "ғ", "г", "қ", "к", "ң", "н",
+	"ө", "о", "ұ", "у", "ү", "у", "һ", "х", "і", "и",
+)
+
+// Normalize приводит текст к виду для сравнения: нижний регистр, без эмодзи
+// и пунктуации, ё → е, казахские буквы → русские, одиночные пробелы.
+func Normalize(s string) string {
+	s = fold.Replace(strings.ToLower(s))
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Synonym — фраза, которая ведёт на slug контента.
+type Synonym struct {
+	Lang   string
+	Phrase string
+	Slug   string
+}
+
+// Resolver сопоставляет короткий текст пользователя со slug раздела:
+// без учёта регистра и эмодзи, по синонимам и с допуском опечаток.
+// Статические фразы задаются Add, синонимы из базы — SetSynonyms.
+// Фраза привязана к языку и совпадает только с текстом на этом языке;
+// пустой язык — фраза годится для любого.
+type Resolver struct {
+	mu      sync.RWMutex
+	static  map[phrase]string
+	dynamic map[phrase]string
+}
+
+type phrase struct {
+	lang string
+	text string
+}
+
+func New() *Resolver {
+	return &Resolver{static: map[phrase]string{}, dynamic: map[phrase]string{}}
+}
+
+// Add добавляет фразы языка lang ("" — любого), ведущие на slug.
+func (r *Resolver) Add(lang, slug string, phrases ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range phrases {
+		if n := Normalize(p); n != "" {
+			r.static[phrase{lang, n}] = slug
+		}
+	}
+}
+
+// SetSynonyms заменяет синонимы из базы целиком.
+func (r *Resolver) SetSynonyms(syns []Synonym) {
+	m := make(map[phrase]string, len(syns))
+	for _, s := range syns {
+		if n := Normalize(s.Phrase); n != "" {
+			m[phrase{s.Lang, n}] = s.Slug
+		}
+	}
+	r.mu.Lock()
+	r.dynamic = m
+	r.mu.Unlock()
+}
+
+// Resolve возвращает slug для text на языке lang. Если ближайших фраз с
+// разными slug несколько, совпадения нет: лучше спросить NLP, чем открыть
+// не тот раздел.
+func (r *Resolver) Resolve(text, lang string) (string, bool) {
+	n := Normalize(text)
+	if n == "" || len(strings.Fields(n)) > maxWords {
+		return "", false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, m := range []map[phrase]string{r.dynamic, r.static} {
+		for _, l := range []string{lang, ""} {
+			if slug, ok := m[phrase{l, n}]; ok {
+				return slug, true
+			}
+		}
+	}
+
+	limit := tolerance(n)
+	if limit == 0 {
+		return "", false
+	}
+	best, bestSlug, ambiguous := limit+1, "", false
+	for _, m := range []map[phrase]string{r.dynamic, r.static} {
+		for p, slug := range m {
+			if p.lang != "" && p.lang != lang {
+				continue
+			}
+			d := distance(n, p.text, limit)
+			switch {
+			case d < best:
+				best, bestSlug, ambiguous = d, slug, false
+			case d == best && slug != bestSlug:
+				ambiguous = true
+			}
+		}
+	}
+	if best > limit || ambiguous {
+		return "", false
+	}
+	return bestSlug, true
+}
+
+// tolerance — сколько опечаток прощать: в коротких словах ни одной,
+// иначе «дом» превратится в «док».
+func tolerance(s string) int {
+	switch n := len([]rune(s)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// distance — расстояние Левенштейна по рунам; всё, что больше limit,
+// возвращается как limit+1.
+func distance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+	return min(prev[len(rb)], limit+1)
+}
+
+// LoadSynonyms читает синонимы tenant из таблицы synonyms (см. init.sql).
+func LoadSynonyms(ctx context.Context, db *pgxpool.Pool, tenant string) ([]Synonym, error) {
+	rows, err := db.Query(ctx, `select lang, phrase, slug from synonyms where tenant=$1`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Synonym
+	for rows.Next() {
+		var s Synonym
+		if err := rows.Scan(&s.Lang, &s.Phrase, &s.Slug); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,190 @@
+package router
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Context — то, что получает обработчик маршрута: исходный апдейт
+// и уже разобранные из него части.
+type Context struct {
+	Update  tgbotapi.Update
+	ChatID  int64
+	UserID  int64
+	Text    string
+	Command string
+	Args    string
+	Match   []string
+	Data    string
+}
+
+type HandlerFunc func(c *Context)
+
+const (
+	KindCommand  = "command"
+	KindText     = "text"
+	KindRegex    = "regex"
+	KindCallback = "callback"
+)
+
+// Route описывает зарегистрированный маршрут, например для /help.
+type Route struct {
+	Kind        string
+	Pattern     string
+	Description string
+}
+
+type regexRoute struct {
+	re *regexp.Regexp
+	h  HandlerFunc
+}
+
+type callbackRoute struct {
+	prefix string
+	h      HandlerFunc
+}
+
+// InterceptFunc получает сообщение раньше текстовых маршрутов и возвращает
+// true, если обработало его само (например, идёт пошаговый диалог).
+type InterceptFunc func(c *Context) bool
+
+// Router сопоставляет апдейт с обработчиком в фиксированном порядке:
+// callback data → команда → intercept → точный текст кнопки → регулярное
+// выражение → fallback.
+// Регистрация маршрутов должна закончиться до первого вызова Handle.
+type Router struct {
+	commands  map[string]HandlerFunc
+	texts     map[string]HandlerFunc
+	regexes   []regexRoute
+	callbacks []callbackRoute
+	fallback  HandlerFunc
+	staleCb   HandlerFunc
+	intercept InterceptFunc
+	routes    []Route
+}
+
+func New() *Router {
+	return &Router{
+		commands: map[string]HandlerFunc{},
+		texts:    map[string]HandlerFunc{},
+	}
+}
+
+func (r *Router) Command(name, description string, h HandlerFunc) {
+	name = strings.TrimPrefix(name, "/")
+	r.commands[name] = h
+	r.routes = append(r.routes, Route{Kind: KindCommand, Pattern: name, Description: description})
+}
+
+func (r *Router) Text(h HandlerFunc, labels ...string) {
+	for _, l := range labels {
+		r.texts[l] = h
+		r.routes = append(r.routes, Route{Kind: KindText, Pattern: l})
+	}
+}
+
+func (r *Router) Regex(re *regexp.Regexp, h HandlerFunc) {
+	r.regexes = append(r.regexes, regexRoute{re: re, h: h})
+	r.routes = append(r.routes, Route{Kind: KindRegex, Pattern: re.String()})
+}
+
+// Callback регистрирует обработчик для callback data с данным префиксом.
+// Побеждает самый длинный совпавший префикс.
+func (r *Router) Callback(prefix string, h HandlerFunc) {
+	r.callbacks = append(r.callbacks, callbackRoute{prefix: prefix, h: h})
+	sort.SliceStable(r.callbacks, func(i, j int) bool {
+		return len(r.callbacks[i].prefix) > len(r.callbacks[j].prefix)
+	})
+	r.routes = append(r.routes, Route{Kind: KindCallback, Pattern: prefix})
+}
+
+func (r *Router) Fallback(h HandlerFunc) { r.fallback = h }
+
+// CallbackFallback получает callback, не подошедший ни к одному префиксу,
+// — обычно кнопку из старого сообщения. Обработчик должен ответить на
+// callback, иначе у пользователя будет крутиться индикатор загрузки.
+func (r *Router) CallbackFallback(h HandlerFunc) { r.staleCb = h }
+
+// Intercept ставит перехватчик, через который проходят все сообщения,
+// кроме зарегистрированных команд.
+func (r *Router) Intercept(h InterceptFunc) { r.intercept = h }
+
+func (r *Router) Routes() []Route {
+	return append([]Route(nil), r.routes...)
+}
+
+// Commands возвращает команды в порядке регистрации для setMyCommands.
+func (r *Router) Commands() []tgbotapi.BotCommand {
+	var out []tgbotapi.BotCommand
+	for _, rt := range r.routes {
+		if rt.Kind == KindCommand && rt.Description != "" {
+			out = append(out, tgbotapi.BotCommand{Command: rt.Pattern, Description: rt.Description})
+		}
+	}
+	return out
+}
+
+// Handle находит обработчик для апдейта и вызывает его.
+// Возвращает false, если апдейт не подошёл ни к одному маршруту.
+func (r *Router) Handle(upd tgbotapi.Update) bool {
+	if cq := upd.CallbackQuery; cq != nil {
+		c := &Context{Update: upd, UserID: cq.From.ID, Data: cq.Data}
+		if cq.Message != nil {
+			c.ChatID = cq.Message.Chat.ID
+		} else {
+			c.ChatID = cq.From.ID
+		}
+		for _, cb := range r.callbacks {
+			if strings.HasPrefix(cq.Data, cb.prefix) {
+				c.Args = strings.TrimPrefix(cq.Data, cb.prefix)
+				cb.h(c)
+				return true
+			}
+		}
+		if r.staleCb != nil {
+			r.staleCb(c)
+			return true
+		}
+		return false
+	}
+
+	m := upd.Message
+	if m == nil {
+		return false
+	}
+	c := &Context{Update: upd, ChatID: m.Chat.ID, Text: m.Text}
+	if m.From != nil {
+		c.UserID = m.From.ID
+	}
+
+	if m.IsCommand() {
+		if h, ok := r.commands[m.Command()]; ok {
+			c.Command = m.Command()
+			c.Args = strings.TrimSpace(m.CommandArguments())
+			h(c)
+			return true
+		}
+	}
+	if r.intercept != nil && r.intercept(c) {
+		return true
+	}
+	if h, ok := r.texts[m.Text]; ok {
+		h(c)
+		return true
+	}
+	for _, rr := range r.regexes {
+		if sm := rr.re.FindStringSubmatch(m.Text); sm != nil {
+			c.Match = sm
+			rr.h(c)
+			return true
+		}
+	}
+	if r.fallback != nil {
+		r.fallback(c)
+		return true
+	}
+	return false
+}
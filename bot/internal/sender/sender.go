@@ -0,0 +1,96 @@
+package sender
+
+import (
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender — часть Bot API, которой пользуются хендлеры. Ему удовлетворяют
+// *tgbotapi.BotAPI, outbox.Outbox и Recorder для тестов.
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+}
+
+var _ Sender = (*tgbotapi.BotAPI)(nil)
+
+// Recorder ничего не отправляет, а запоминает всё, что ему передали.
+type Recorder struct {
+	mu     sync.Mutex
+	sent   []tgbotapi.Chattable
+	nextID int
+}
+
+func NewRecorder() *Recorder { return &Recorder{} }
+
+func (r *Recorder) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, c)
+	r.nextID++
+	msg := tgbotapi.Message{MessageID: r.nextID, Text: Text(c)}
+	if id := ChatID(c); id != 0 {
+		msg.Chat = &tgbotapi.Chat{ID: id}
+	}
+	return msg, nil
+}
+
+func (r *Recorder) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, c)
+	return &tgbotapi.APIResponse{Ok: true, Result: []byte("true")}, nil
+}
+
+func (r *Recorder) Sent() []tgbotapi.Chattable {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]tgbotapi.Chattable(nil), r.sent...)
+}
+
+// Texts возвращает тексты отправленных и отредактированных сообщений по порядку.
+func (r *Recorder) Texts() []string {
+	var out []string
+	for _, c := range r.Sent() {
+		if t := Text(c); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = nil
+}
+
+// Text достаёт пользовательский текст из известных типов запросов.
+func Text(c tgbotapi.Chattable) string {
+	switch v := c.(type) {
+	case tgbotapi.MessageConfig:
+		return v.Text
+	case tgbotapi.EditMessageTextConfig:
+		return v.Text
+	case tgbotapi.DocumentConfig:
+		return v.Caption
+	case tgbotapi.CallbackConfig:
+		return v.Text
+	}
+	return ""
+}
+
+func ChatID(c tgbotapi.Chattable) int64 {
+	switch v := c.(type) {
+	case tgbotapi.MessageConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return v.ChatID
+	case tgbotapi.DocumentConfig:
+		return v.ChatID
+	}
+	return 0
+}
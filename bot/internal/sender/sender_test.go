@@ -0,0 +1,34 @@
+package sender
+
+import (
+	"slices"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+	msg, err := r.Send(tgbotapi.NewMessage(42, "Выберите раздел:"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.MessageID != 1 || msg.Chat == nil || msg.Chat.ID != 42 {
+		t.Fatalf("Send result = %+v", msg)
+	}
+	r.Send(tgbotapi.NewEditMessageText(42, msg.MessageID, "Гранты"))
+	if _, err := r.Request(tgbotapi.NewCallback("cb1", "")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(r.Sent()); got != 3 {
+		t.Fatalf("recorded %d, want 3", got)
+	}
+	if got, want := r.Texts(), []string{"Выберите раздел:", "Гранты"}; !slices.Equal(got, want) {
+		t.Fatalf("Texts() = %q, want %q", got, want)
+	}
+	r.Reset()
+	if len(r.Sent()) != 0 {
+		t.Fatal("Reset kept requests")
+	}
+}
@@ -0,0 +1,62 @@
+package session
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+)
+
+var metrics = expvar.NewMap("sessions")
+
+// Metrics — счётчики сессий одного бота в expvar: sessions.<bot>.live и т. д.
+type Metrics struct {
+	Live    *expvar.Int
+	Expired *expvar.Int
+	Evicted *expvar.Int
+}
+
+func NewMetrics(bot string) *Metrics {
+	m := &Metrics{Live: new(expvar.Int), Expired: new(expvar.Int), Evicted: new(expvar.Int)}
+	bm := new(expvar.Map).Init()
+	bm.Set("live", m.Live)
+	bm.Set("expired_total", m.Expired)
+	bm.Set("evicted_total", m.Evicted)
+	metrics.Set(bot, bm)
+	return m
+}
+
+// RunJanitor раз в every вычищает неактивные сессии и обновляет метрики,
+// пока не отменён ctx.
+func RunJanitor(ctx context.Context, s Sweeper, every time.Duration, m *Metrics) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		sweep(ctx, s, m)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func sweep(ctx context.Context, s Sweeper, m *Metrics) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	res, err := s.Sweep(ctx, time.Now())
+	if err != nil {
+		log.Printf("session sweep: %v", err)
+	}
+	m.Expired.Add(int64(res.Expired))
+	m.Evicted.Add(int64(res.Evicted))
+	n, err := s.Len(ctx)
+	if err != nil {
+		log.Printf("session count: %v", err)
+		return
+	}
+	m.Live.Set(int64(n))
+	if res.Expired > 0 || res.Evicted > 0 {
+		log.Printf("sessions: %d live, %d expired, %d evicted", n, res.Expired, res.Evicted)
+	}
+}
@@ -0,0 +1,128 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres хранит сессии в таблице bot_sessions (см. init.sql), по строке
+// на чат. Bot разделяет данные разных ботов одного процесса. Сессия старше
+// TTL не читается сразу, как и в Memory, а удаляется в Sweep; MaxSessions
+// тоже применяется в Sweep и ограничивает число сессий всех ботов в
+// таблице вместе. Нули — без ограничений.
+type Postgres struct {
+	DB  *pgxpool.Pool
+	Bot string
+
+	TTL         time.Duration
+	MaxSessions int
+}
+
+func NewPostgres(db *pgxpool.Pool, bot string) *Postgres {
+	return &Postgres{DB: db, Bot: bot}
+}
+
+// cutoff — раньше какого updated_at сессия считается истёкшей; nil — TTL нет.
+func (p *Postgres) cutoff() *time.Time {
+	if p.TTL <= 0 {
+		return nil
+	}
+	t := time.Now().Add(-p.TTL)
+	return &t
+}
+
+func (p *Postgres) Load(ctx context.Context, chatID int64) (Session, error) {
+	var raw []byte
+	err := p.DB.QueryRow(ctx,
+		`select data from bot_sessions where bot=$1 and chat_id=$2
+and ($3::timestamptz is null or updated_at >= $3)`, p.Bot, chatID, p.cutoff()).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Session{ChatID: chatID}, nil
+	}
+	if err != nil {
+		return Session{}, err
+	}
+	return decode(raw)
+}
+
+// Update блокирует строку чата (select ... for update) на время fn,
+// поэтому параллельные обновления одного чата не теряют друг друга.
+func (p *Postgres) Update(ctx context.Context, chatID int64, fn func(*Session) error) (Session, error) {
+	var s Session
+	err := pgx.BeginFunc(ctx, p.DB, func(tx pgx.Tx) error {
+		var raw []byte
+		err := tx.QueryRow(ctx,
+			`select data from bot_sessions where bot=$1 and chat_id=$2
+and ($3::timestamptz is null or updated_at >= $3) for update`, p.Bot, chatID, p.cutoff()).Scan(&raw)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// нет или истекла: истёкшую строку перезапишет upsert ниже
+			s = Session{ChatID: chatID}
+		case err != nil:
+			return err
+		default:
+			if s, err = decode(raw); err != nil {
+				return err
+			}
+		}
+
+		if err := fn(&s); err != nil {
+			return err
+		}
+		s.ChatID = chatID
+		s.LastActive = time.Now()
+		data, err := encode(s)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+insert into bot_sessions (bot, chat_id, data, updated_at)
+values ($1, $2, $3, $4)
+on conflict (bot, chat_id) do update set data = excluded.data, updated_at = excluded.updated_at`,
+			p.Bot, chatID, data, s.LastActive)
+		return err
+	})
+	return s, err
+}
+
+func (p *Postgres) Delete(ctx context.Context, chatID int64) error {
+	_, err := p.DB.Exec(ctx, `delete from bot_sessions where bot=$1 and chat_id=$2`, p.Bot, chatID)
+	return err
+}
+
+func (p *Postgres) Sweep(ctx context.Context, now time.Time) (SweepResult, error) {
+	var res SweepResult
+	if p.TTL > 0 {
+		tag, err := p.DB.Exec(ctx,
+			`delete from bot_sessions where bot=$1 and updated_at < $2`, p.Bot, now.Add(-p.TTL))
+		if err != nil {
+			return res, err
+		}
+		res.Expired = int(tag.RowsAffected())
+	}
+	if p.MaxSessions > 0 {
+		// лимит общий: каждый бот удаляет свои сессии, не вошедшие в
+		// MaxSessions самых свежих по всем ботам, — так вытеснения
+		// попадают в метрики своего бота
+		tag, err := p.DB.Exec(ctx, `
+delete from bot_sessions where bot=$1 and (bot, chat_id) in (
+  select bot, chat_id from bot_sessions
+  order by updated_at desc offset $2
+)`, p.Bot, p.MaxSessions)
+		if err != nil {
+			return res, err
+		}
+		res.Evicted = int(tag.RowsAffected())
+	}
+	return res, nil
+}
+
+func (p *Postgres) Len(ctx context.Context) (int, error) {
+	var n int
+	err := p.DB.QueryRow(ctx, `select count(*) from bot_sessions where bot=$1`, p.Bot).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"telegramBot/bot/internal/profile"
+)
+
+// Version — текущая версия формата, в котором Session лежит в хранилище.
+// При несовместимом изменении структуры версия растёт, а decode учится
+// поднимать старые записи до текущей.
+const Version = 1
+
+// Session — всё, что бот помнит о чате.
+type Session struct {
+	ChatID int64 `json:"chat_id"`
+
+	Lang string `json:"lang,omitempty"`
+	// LangAuto — язык угадан ботом, а не выбран пользователем, и может
+	// смениться, если человек пишет на другом языке.
+	LangAuto bool `json:"lang_auto,omitempty"`
+	// Role — кто пишет: applicant, parent, student; пусто — неизвестно.
+	Role string `json:"role,omitempty"`
+	// Node — код текущего узла меню.
+	Node string `json:"node,omitempty"`
+	// NavStack — узлы, через которые пользователь пришёл в Node, для «Назад».
+	NavStack []string `json:"nav,omitempty"`
+	// Smalltalk — сколько следующих сообщений считать болтовнёй.
+	Smalltalk int       `json:"smalltalk,omitempty"`
+	History   []Message `json:"history,omitempty"`
+	// LegacyProfile — профиль, сохранённый в сессии прежними версиями бота.
+	// Теперь профиль живёт в profile.Store; при первом обращении он
+	// переносится туда, а поле очищается.
+	LegacyProfile *profile.Profile `json:"profile,omitempty"`
+	// Flow — незавершённый пошаговый диалог (см. пакет fsm).
+	Flow *FlowState `json:"flow,omitempty"`
+
+	LastActive time.Time `json:"last_active"`
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// FlowState — позиция в пошаговом диалоге и уже собранные ответы.
+type FlowState struct {
+	Name    string            `json:"name"`
+	State   string            `json:"state"`
+	Data    map[string]string `json:"data,omitempty"`
+	Expires time.Time         `json:"expires"`
+}
+
+// PushHistory добавляет реплику, оставляя не больше limit последних.
+func (s *Session) PushHistory(role, content string, limit int) {
+	s.History = append(s.History, Message{Role: role, Content: content})
+	if limit > 0 && len(s.History) > limit {
+		s.History = append([]Message(nil), s.History[len(s.History)-limit:]...)
+	}
+}
+
+type envelope struct {
+	V       int             `json:"v"`
+	Session json.RawMessage `json:"session"`
+}
+
+func encode(s Session) ([]byte, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope{V: Version, Session: raw})
+}
+
+func decode(raw []byte) (Session, error) {
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return Session{}, err
+	}
+	var s Session
+	switch env.V {
+	case 1:
+		err := json.Unmarshal(env.Session, &s)
+		return s, err
+	default:
+		return s, fmt.Errorf("session: unsupported version %d", env.V)
+	}
+}
@@ -0,0 +1,162 @@
+package session
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Store хранит Session каждого чата.
+type Store interface {
+	// Load возвращает сессию чата; для нового чата — пустую с заполненным ChatID.
+	Load(ctx context.Context, chatID int64) (Session, error)
+	// Update атомарно читает сессию, применяет к ней fn и сохраняет результат.
+	// Если fn вернула ошибку, сессия не меняется. LastActive выставляется сам.
+	Update(ctx context.Context, chatID int64, fn func(*Session) error) (Session, error)
+	Delete(ctx context.Context, chatID int64) error
+}
+
+// Sweeper — хранилище, из которого janitor вычищает неактивные сессии.
+type Sweeper interface {
+	// Sweep удаляет сессии, неактивные дольше TTL, и самые старые сверх лимита.
+	// Результат включает вытеснения, случившиеся между вызовами.
+	Sweep(ctx context.Context, now time.Time) (SweepResult, error)
+	Len(ctx context.Context) (int, error)
+}
+
+type SweepResult struct {
+	Expired int
+	Evicted int
+}
+
+// Memory — Store в памяти процесса. TTL и MaxSessions ограничивают его рост:
+// просроченная сессия не видна сразу, а удаляется при Sweep; при превышении
+// MaxSessions сразу вытесняется давно не использованный чат. Нули — без ограничений.
+// Сессии хранятся сериализованными, как и в базе, поэтому наружу всегда
+// отдаётся копия.
+type Memory struct {
+	TTL         time.Duration
+	MaxSessions int
+
+	mu      sync.Mutex
+	chats   map[int64]*list.Element
+	lru     *list.List // front — самый свежий
+	evicted int
+}
+
+type memEntry struct {
+	chatID   int64
+	data     []byte
+	lastUsed time.Time
+}
+
+func NewMemory(ttl time.Duration, maxSessions int) *Memory {
+	return &Memory{
+		TTL:         ttl,
+		MaxSessions: maxSessions,
+		chats:       map[int64]*list.Element{},
+		lru:         list.New(),
+	}
+}
+
+// entry возвращает живую запись чата и отмечает её использованной.
+func (m *Memory) entry(chatID int64, now time.Time) *memEntry {
+	el, ok := m.chats[chatID]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*memEntry)
+	if m.TTL > 0 && now.Sub(e.lastUsed) > m.TTL {
+		return nil
+	}
+	e.lastUsed = now
+	m.lru.MoveToFront(el)
+	return e
+}
+
+func (m *Memory) remove(chatID int64) {
+	if el, ok := m.chats[chatID]; ok {
+		m.lru.Remove(el)
+		delete(m.chats, chatID)
+	}
+}
+
+func (m *Memory) load(chatID int64, now time.Time) (Session, error) {
+	e := m.entry(chatID, now)
+	if e == nil {
+		return Session{ChatID: chatID}, nil
+	}
+	return decode(e.data)
+}
+
+func (m *Memory) Load(_ context.Context, chatID int64) (Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.load(chatID, time.Now())
+}
+
+func (m *Memory) Update(_ context.Context, chatID int64, fn func(*Session) error) (Session, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.load(chatID, now)
+	if err != nil {
+		return s, err
+	}
+	if err := fn(&s); err != nil {
+		return s, err
+	}
+	s.ChatID = chatID
+	s.LastActive = now
+	data, err := encode(s)
+	if err != nil {
+		return s, err
+	}
+
+	if e := m.entry(chatID, now); e != nil {
+		e.data = data
+		return s, nil
+	}
+	m.remove(chatID)
+	m.chats[chatID] = m.lru.PushFront(&memEntry{chatID: chatID, data: data, lastUsed: now})
+	for m.MaxSessions > 0 && m.lru.Len() > m.MaxSessions {
+		m.remove(m.lru.Back().Value.(*memEntry).chatID)
+		m.evicted++
+	}
+	return s, nil
+}
+
+func (m *Memory) Delete(_ context.Context, chatID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(chatID)
+	return nil
+}
+
+func (m *Memory) Sweep(_ context.Context, now time.Time) (SweepResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := SweepResult{Evicted: m.evicted}
+	m.evicted = 0
+	if m.TTL <= 0 {
+		return res, nil
+	}
+	// список упорядочен по lastUsed, поэтому просроченные — в хвосте
+	for el := m.lru.Back(); el != nil; el = m.lru.Back() {
+		e := el.Value.(*memEntry)
+		if now.Sub(e.lastUsed) <= m.TTL {
+			break
+		}
+		m.remove(e.chatID)
+		res.Expired++
+	}
+	return res, nil
+}
+
+func (m *Memory) Len(context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len(), nil
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const SecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Receiver принимает апдейты, которые Telegram (или обратный прокси) POST-ит
+// на webhook, и складывает их в канал Updates — тот же интерфейс, что и у
+// GetUpdatesChan, поэтому остальной код не знает, откуда пришёл апдейт.
+type Receiver struct {
+	Secret  string
+	Updates chan tgbotapi.Update
+}
+
+func New(secret string, buffer int) *Receiver {
+	return &Receiver{Secret: secret, Updates: make(chan tgbotapi.Update, buffer)}
+}
+
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// без секрета не пускаем никого: иначе апдейт может прислать любой
+	got := r.Header.Get(SecretHeader)
+	if rc.Secret == "" || subtle.ConstantTimeCompare([]byte(got), []byte(rc.Secret)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var upd tgbotapi.Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&upd); err != nil {
+		http.Error(w, "bad update", http.StatusBadRequest)
+		return
+	}
+	select {
+	case rc.Updates <- upd:
+		w.WriteHeader(http.StatusOK)
+	case <-r.Context().Done():
+		// Telegram повторит доставку, если не получит 2xx.
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}
+}
+
+// Register сообщает Telegram адрес webhook-а вместе с секретом, который
+// затем приходит в заголовке SecretHeader каждого запроса.
+func Register(api *tgbotapi.BotAPI, url, secret string) error {
+	params := tgbotapi.Params{"url": url}
+	params.AddNonEmpty("secret_token", secret)
+	_, err := api.MakeRequest("setWebhook", params)
+	return err
+}
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBot/bot/internal/dispatcher"
+)
+
+const secret = "s3cret"
+
+func post(t *testing.T, rc *Receiver, secretHeader, body string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	if secretHeader != "" {
+		req.Header.Set(SecretHeader, secretHeader)
+	}
+	rec := httptest.NewRecorder()
+	rc.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestReceiverRejects(t *testing.T) {
+	valid := `{"update_id": 1, "message": {"message_id": 1, "chat": {"id": 42, "type": "private"}, "text": "hi"}}`
+	tests := []struct {
+		name   string
+		secret string // секрет получателя
+		header string
+		body   string
+		want   int
+	}{
+		{"missing secret", secret, "", valid, http.StatusUnauthorized},
+		{"wrong secret", secret, "nope", valid, http.StatusUnauthorized},
+		{"receiver without secret", "", "", valid, http.StatusUnauthorized},
+		{"malformed body", secret, secret, `{"update_id": `, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := New(tt.secret, 1)
+			if got := post(t, rc, tt.header, tt.body); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+			if len(rc.Updates) != 0 {
+				t.Fatalf("rejected update was queued")
+			}
+		})
+	}
+}
+
+func TestReceiverMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(secret, 1).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhook", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestReceiverDeliversToDispatcher(t *testing.T) {
+	got := make(chan tgbotapi.Update, 1)
+	d := dispatcher.New(1, 1, func(upd tgbotapi.Update) { got <- upd })
+	d.Start()
+	defer d.Stop()
+
+	rc := New(secret, 1)
+	go func() {
+		for upd := range rc.Updates {
+			d.Dispatch(upd)
+		}
+	}()
+	defer close(rc.Updates)
+
+	body := `{"update_id": 7, "message": {"message_id": 3, "chat": {"id": 42, "type": "private"}, "text": "/start"}}`
+	if code := post(t, rc, secret, body); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	select {
+	case upd := <-got:
+		if upd.UpdateID != 7 || upd.Message == nil || upd.Message.Chat.ID != 42 || upd.Message.Text != "/start" {
+			t.Fatalf("unexpected update %+v", upd)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("update was not handled")
+	}
+}
+
+func TestReceiverBusy(t *testing.T) {
+	rc := New(secret, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id": 1}`)).WithContext(ctx)
+	req.Header.Set(SecretHeader, secret)
+	rec := httptest.NewRecorder()
+	rc.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"time"
+
 	"telegramBot/content-api/internal/db"
 	h "telegramBot/content-api/internal/http"
 )
@@ -10,7 +14,20 @@ import (
 func main() {
 	pool := db.MustPool()
 	defer pool.Close()
-	s := &h.Server{DB: pool}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	err := db.Migrate(ctx, pool)
+	cancel()
+	if err != nil {
+		log.Fatalf("migrate: %v", err)
+	}
+	tokens, err := h.ParseTokens(os.Getenv("API_TOKENS"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(tokens) == 0 {
+		log.Println("API_TOKENS пустой — API управления меню закрыт")
+	}
+	s := &h.Server{DB: pool, Tokens: tokens}
 	log.Println("content-api listening on :8080")
 	log.Fatal(http.ListenAndServe(":8080", s.Routes()))
 }
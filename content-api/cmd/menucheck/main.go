@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"telegramBot/content-api/internal/db"
+	"telegramBot/content-api/internal/menucheck"
+	"telegramBot/content-api/internal/repo"
+)
+
+// menucheck проверяет меню в базе перед публикацией изменений и
+// завершается с кодом 1, если нашёл ошибки.
+//
+//	DATABASE_URL=... go run ./content-api/cmd/menucheck -root main
+func main() {
+	root := flag.String("root", "", "code of the root menu node (menu_root of the bot), required")
+	tenant := flag.String("tenant", repo.DefaultTenant, "tenant whose menu and content to check")
+	asJSON := flag.Bool("json", false, "print problems as JSON")
+	flag.Parse()
+	if *root == "" {
+		// у каждого бота свой корень, угадывать его нельзя
+		log.Fatal("-root is required: pass menu_root of the bot whose menu you check")
+	}
+
+	pool := db.MustPool()
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	m, err := menucheck.Load(ctx, pool, *tenant)
+	if err != nil {
+		log.Fatal(err)
+	}
+	problems := menucheck.Check(m, *root)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(problems)
+	} else {
+		for _, p := range problems {
+			where := p.Node
+			if p.Button != 0 {
+				where = fmt.Sprintf("%s#%d", p.Node, p.Button)
+			}
+			fmt.Printf("%-7s %-19s %-16s %s\n", p.Severity, p.Kind, where, p.Detail)
+		}
+		fmt.Printf("%d nodes, %d buttons, %d problems\n", len(m.Nodes), len(m.Buttons), len(problems))
+	}
+	if menucheck.HasErrors(problems) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,18 @@
+package db
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrate.sql
+var migrateSQL string
+
+// Migrate доводит схему существующей базы до текущей (см. migrate.sql).
+// На базе, только что созданной из init.sql, ничего не меняет.
+func Migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, migrateSQL)
+	return err
+}
@@ -0,0 +1,133 @@
+-- Доводит базу, созданную прежними версиями init.sql, до текущей схемы.
+-- init.sql выполняется только на пустом томе, а этот файл content-api
+-- выполняет при каждом старте, поэтому все шаги идемпотентны. Новую схему
+-- заводите и в init.sql, и здесь.
+
+-- content: tenant, ревизии, мягкое удаление
+ALTER TABLE content ADD COLUMN IF NOT EXISTS tenant TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE content ADD COLUMN IF NOT EXISTS current_rev INT NOT NULL DEFAULT 0;
+ALTER TABLE content ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE content DROP CONSTRAINT IF EXISTS content_slug_lang_key;
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'content_tenant_slug_lang_key') THEN
+        ALTER TABLE content ADD CONSTRAINT content_tenant_slug_lang_key UNIQUE (tenant, slug, lang);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS content_revisions (
+    id            BIGSERIAL   PRIMARY KEY,
+    content_id    INT         NOT NULL REFERENCES content(id),
+    rev           INT         NOT NULL,
+    title         TEXT        NOT NULL,
+    body          TEXT        NOT NULL,
+    author        TEXT        NOT NULL,
+    restored_from INT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (content_id, rev)
+);
+-- ревизии не удаляются вместе с контентом
+DO $$ BEGIN
+    IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'content_revisions_content_id_fkey' AND confdeltype = 'c') THEN
+        ALTER TABLE content_revisions DROP CONSTRAINT content_revisions_content_id_fkey;
+        ALTER TABLE content_revisions ADD CONSTRAINT content_revisions_content_id_fkey
+            FOREIGN KEY (content_id) REFERENCES content(id);
+    END IF;
+END $$;
+
+INSERT INTO content (slug, lang, title, body) VALUES
+    ('hint-region-south','ru','Гранты «Серпін»','Для молодёжи из южных регионов есть отдельные гранты по программе «Серпін» — условия уточните в приёмной комиссии.'),
+    ('hint-region-south','kz','«Серпін» гранттары','Оңтүстік өңірлердің жастарына «Серпін» бағдарламасы бойынша жеке гранттар бөлінеді — шарттарын қабылдау комиссиясынан сұраңыз.')
+ON CONFLICT DO NOTHING;
+
+-- контент без ревизий (заведённый до них или мимо API) — первая ревизия
+INSERT INTO content_revisions (content_id, rev, title, body, author)
+SELECT id, 1, title, body, 'migrate.sql' FROM content WHERE current_rev = 0
+ON CONFLICT DO NOTHING;
+UPDATE content SET current_rev = 1 WHERE current_rev = 0;
+
+-- меню: tenant, раскладка кнопок, выключение кнопок
+ALTER TABLE "узлы_меню" ADD COLUMN IF NOT EXISTS "tenant" TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE "узлы_меню" ADD COLUMN IF NOT EXISTS "columns" INT NOT NULL DEFAULT 1 CHECK ("columns" BETWEEN 1 AND 4);
+ALTER TABLE "узлы_меню" ADD COLUMN IF NOT EXISTS "page_size" INT NOT NULL DEFAULT 0 CHECK ("page_size" BETWEEN 0 AND 50);
+ALTER TABLE "узлы_меню" DROP CONSTRAINT IF EXISTS "узлы_меню_code_key";
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'узлы_меню_tenant_code_key') THEN
+        ALTER TABLE "узлы_меню" ADD CONSTRAINT "узлы_меню_tenant_code_key" UNIQUE ("tenant", "code");
+    END IF;
+END $$;
+
+ALTER TABLE "кнопки" ADD COLUMN IF NOT EXISTS "active" BOOLEAN NOT NULL DEFAULT TRUE;
+-- узел, на который ведут кнопки, удалить нельзя (DeleteNode отвечает 409)
+DO $$ BEGIN
+    IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'кнопки_next_node_id_fkey' AND confdeltype = 'n') THEN
+        ALTER TABLE "кнопки" DROP CONSTRAINT "кнопки_next_node_id_fkey";
+        ALTER TABLE "кнопки" ADD CONSTRAINT "кнопки_next_node_id_fkey"
+            FOREIGN KEY ("next_node_id") REFERENCES "узлы_меню"(id);
+    END IF;
+END $$;
+
+ALTER TABLE "переводы" ADD COLUMN IF NOT EXISTS "tenant" TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE "переводы" DROP CONSTRAINT IF EXISTS "переводы_lang_key_key";
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'переводы_tenant_lang_key_key') THEN
+        ALTER TABLE "переводы" ADD CONSTRAINT "переводы_tenant_lang_key_key" UNIQUE ("tenant", "lang", "key");
+    END IF;
+END $$;
+DROP INDEX IF EXISTS "переводы_key_lang_idx";
+CREATE INDEX IF NOT EXISTS "переводы_tenant_key_lang_idx" ON "переводы"("tenant", "key", "lang");
+
+-- таблицы бота
+CREATE TABLE IF NOT EXISTS bot_sessions (
+    bot        TEXT        NOT NULL,
+    chat_id    BIGINT      NOT NULL,
+    data       JSONB       NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (bot, chat_id)
+);
+CREATE INDEX IF NOT EXISTS bot_sessions_updated_idx ON bot_sessions (bot, updated_at);
+
+CREATE TABLE IF NOT EXISTS bot_profiles (
+    bot        TEXT        NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    data       JSONB       NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (bot, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS privacy_audit (
+    id         BIGSERIAL   PRIMARY KEY,
+    bot        TEXT        NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    action     TEXT        NOT NULL,
+    holders    TEXT[]      NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS privacy_audit_user_idx ON privacy_audit (user_id);
+
+CREATE TABLE IF NOT EXISTS bot_events (
+    id         BIGSERIAL   PRIMARY KEY,
+    bot        TEXT        NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    kind       TEXT        NOT NULL,
+    source     TEXT        NOT NULL DEFAULT '',
+    payload    TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS bot_events_user_idx ON bot_events (bot, user_id);
+CREATE INDEX IF NOT EXISTS bot_events_source_idx ON bot_events (kind, source, created_at);
+
+CREATE TABLE IF NOT EXISTS synonyms (
+    id     BIGSERIAL PRIMARY KEY,
+    tenant TEXT NOT NULL DEFAULT 'default',
+    lang   TEXT NOT NULL CHECK (lang IN ('ru','kz')),
+    phrase TEXT NOT NULL,
+    slug   TEXT NOT NULL,
+    UNIQUE (tenant, lang, phrase)
+);
+ALTER TABLE synonyms ADD COLUMN IF NOT EXISTS tenant TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE synonyms DROP CONSTRAINT IF EXISTS synonyms_lang_phrase_key;
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'synonyms_tenant_lang_phrase_key') THEN
+        ALTER TABLE synonyms ADD CONSTRAINT synonyms_tenant_lang_phrase_key UNIQUE (tenant, lang, phrase);
+    END IF;
+END $$;
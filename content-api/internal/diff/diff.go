@@ -0,0 +1,92 @@
+// Package diff сравнивает тексты построчно — для истории правок контента.
+package diff
+
+import "strings"
+
+// Op — что случилось со строкой: Equal, Delete (была только в старом
+// тексте) или Insert (появилась в новом).
+type Op string
+
+const (
+	Equal  Op = " "
+	Delete Op = "-"
+	Insert Op = "+"
+)
+
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines возвращает построчную разницу между a и b по наибольшей общей
+// подпоследовательности. Тексты контента короткие (до нескольких тысяч
+// символов), так что квадратичной таблицы хватает.
+func Lines(a, b string) []Line {
+	x, y := split(a), split(b)
+	// lcs[i][j] — длина общей подпоследовательности x[i:] и y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	out := make([]Line, 0, max(len(x), len(y)))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, Line{Equal, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, Line{Delete, x[i]})
+			i++
+		default:
+			out = append(out, Line{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		out = append(out, Line{Delete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		out = append(out, Line{Insert, y[j]})
+	}
+	return out
+}
+
+// Changed сообщает, есть ли в разнице хоть одна изменённая строка.
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Unified печатает разницу как в diff -u, без заголовков и контекста.
+func Unified(lines []Line) string {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(string(l.Op))
+		b.WriteString(l.Text)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type ctxKey int
+
+const authorKey ctxKey = 0
+
+// ParseTokens разбирает API_TOKENS вида "anna:s3cret,ops:t0ken" в карту
+// токен → имя автора. Имя потом попадает в историю изменений.
+func ParseTokens(s string) (map[string]string, error) {
+	out := map[string]string{}
+	for i, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(part, ":")
+		if !ok || name == "" || len(token) < 8 {
+			// саму запись не печатаем: там может быть токен
+			return nil, fmt.Errorf("API_TOKENS: запись %d: ожидается name:token (токен от 8 символов)", i+1)
+		}
+		out[token] = name
+	}
+	return out, nil
+}
+
+// auth пропускает запрос только с заголовком Authorization: Bearer <токен>
+// из Tokens. Без настроенных токенов изменяющие эндпоинты закрыты.
+func (s *Server) auth(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || got == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var author string
+		for token, name := range s.Tokens {
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				author = name
+			}
+		}
+		if author == "" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		h(w, r.WithContext(context.WithValue(r.Context(), authorKey, author)))
+	}
+}
+
+// Author — имя владельца токена, с которым пришёл запрос.
+func Author(ctx context.Context) string {
+	a, _ := ctx.Value(authorKey).(string)
+	return a
+}
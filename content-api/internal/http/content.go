@@ -0,0 +1,258 @@
+package http
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"telegramBot/content-api/internal/diff"
+	"telegramBot/content-api/internal/repo"
+)
+
+// Бот отправляет заголовок и текст одним сообщением, а Telegram режет
+// сообщения длиннее 4096 символов.
+const (
+	maxTitle = 200
+	maxBody  = 4000
+)
+
+func (s *Server) contentRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /content/items", s.auth(s.listItems))
+	mux.HandleFunc("POST /content/{slug}/{lang}", s.auth(s.createItem))
+	mux.HandleFunc("PATCH /content/{slug}/{lang}", s.auth(s.updateItem))
+	mux.HandleFunc("DELETE /content/{slug}/{lang}", s.auth(s.deleteItem))
+
+	mux.HandleFunc("GET /content/{slug}/{lang}/revisions", s.auth(s.listRevisions))
+	mux.HandleFunc("GET /content/{slug}/{lang}/revisions/{rev}", s.auth(s.getRevision))
+	mux.HandleFunc("GET /content/{slug}/{lang}/diff", s.auth(s.diffRevisions))
+	mux.HandleFunc("POST /content/{slug}/{lang}/rollback", s.auth(s.rollback))
+}
+
+func (s *Server) listItems(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	items, err := repo.ListItems(r.Context(), s.DB, tenant)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (s *Server) createItem(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	it := repo.Item{Tenant: tenant, Slug: r.PathValue("slug"), Lang: r.PathValue("lang"), Title: req.Title, Body: req.Body}
+	if msg := validateItem(it.Slug, it.Lang, &it.Title, &it.Body); msg != "" {
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	it, err := repo.CreateItem(r.Context(), s.DB, it, Author(r.Context()))
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("content: %s created %s/%s/%s rev %d", Author(r.Context()), tenant, it.Slug, it.Lang, it.Rev)
+	writeJSON(w, http.StatusCreated, it)
+}
+
+func (s *Server) updateItem(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	var p repo.ItemPatch
+	if !decode(w, r, &p) {
+		return
+	}
+	slug, lang := r.PathValue("slug"), r.PathValue("lang")
+	if p.Title == nil && p.Body == nil {
+		http.Error(w, "nothing to update: pass title and/or body", http.StatusUnprocessableEntity)
+		return
+	}
+	if msg := validateItem(slug, lang, p.Title, p.Body); msg != "" {
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	it, err := repo.UpdateItem(r.Context(), s.DB, tenant, slug, lang, p, Author(r.Context()))
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("content: %s updated %s/%s/%s rev %d", Author(r.Context()), tenant, slug, lang, it.Rev)
+	writeJSON(w, http.StatusOK, it)
+}
+
+func (s *Server) deleteItem(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	slug, lang := r.PathValue("slug"), r.PathValue("lang")
+	if err := repo.DeleteItem(r.Context(), s.DB, tenant, slug, lang); err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("content: %s deleted %s/%s/%s", Author(r.Context()), tenant, slug, lang)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listRevisions(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	revs, err := repo.ListRevisions(r.Context(), s.DB, tenant, r.PathValue("slug"), r.PathValue("lang"))
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, revs)
+}
+
+func (s *Server) getRevision(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	rev, ok := parseRev(w, r.PathValue("rev"))
+	if !ok {
+		return
+	}
+	v, err := repo.GetRevision(r.Context(), s.DB, tenant, r.PathValue("slug"), r.PathValue("lang"), rev)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+// diffRevisions сравнивает ревизии from и to построчно; без to — с текущей.
+func (s *Server) diffRevisions(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	slug, lang := r.PathValue("slug"), r.PathValue("lang")
+	q := r.URL.Query()
+	from, ok := parseRev(w, q.Get("from"))
+	if !ok {
+		return
+	}
+	var to int
+	if q.Get("to") == "" {
+		var err error
+		if to, err = repo.CurrentRev(r.Context(), s.DB, tenant, slug, lang); err != nil {
+			repoError(w, err)
+			return
+		}
+		if to == 0 {
+			// строка заведена мимо API и ещё не получила первую ревизию
+			http.Error(w, "content has no revisions yet", http.StatusConflict)
+			return
+		}
+	} else if to, ok = parseRev(w, q.Get("to")); !ok {
+		return
+	}
+
+	a, err := repo.GetRevision(r.Context(), s.DB, tenant, slug, lang, from)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	b, err := repo.GetRevision(r.Context(), s.DB, tenant, slug, lang, to)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	title, body := diff.Lines(a.Title, b.Title), diff.Lines(a.Body, b.Body)
+	if q.Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "--- rev %d (%s)\n+++ rev %d (%s)\n%s%s", a.Rev, a.Author, b.Rev, b.Author,
+			diff.Unified(title), diff.Unified(body))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"from":    a.Rev,
+		"to":      b.Rev,
+		"changed": diff.Changed(title) || diff.Changed(body),
+		"title":   title,
+		"body":    body,
+	})
+}
+
+func (s *Server) rollback(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Rev int `json:"rev"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Rev <= 0 {
+		http.Error(w, "rev must be a positive revision number", http.StatusUnprocessableEntity)
+		return
+	}
+	slug, lang := r.PathValue("slug"), r.PathValue("lang")
+	it, err := repo.Rollback(r.Context(), s.DB, tenant, slug, lang, req.Rev, Author(r.Context()))
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("content: %s rolled back %s/%s/%s to rev %d (now rev %d)", Author(r.Context()), tenant, slug, lang, req.Rev, it.Rev)
+	writeJSON(w, http.StatusOK, it)
+}
+
+// validateItem проверяет ключ и поля контента; nil — поле не меняется.
+func validateItem(slug, lang string, title, body *string) string {
+	switch {
+	case !reSlug.MatchString(slug):
+		return "slug must match " + reSlug.String()
+	case lang != "ru" && lang != "kz":
+		return "lang must be ru or kz"
+	case title != nil && (strings.TrimSpace(*title) == "" || utf8.RuneCountInString(*title) > maxTitle):
+		return "title must be 1.." + strconv.Itoa(maxTitle) + " characters"
+	case body != nil && (strings.TrimSpace(*body) == "" || utf8.RuneCountInString(*body) > maxBody):
+		return "body must be 1.." + strconv.Itoa(maxBody) + " characters"
+	}
+	return ""
+}
+
+func parseRev(w http.ResponseWriter, s string) (int, bool) {
+	rev, err := strconv.Atoi(s)
+	if err != nil || rev <= 0 {
+		http.Error(w, "bad rev", http.StatusBadRequest)
+		return 0, false
+	}
+	return rev, true
+}
+
+// tenantOf берёт tenant из query, по умолчанию repo.DefaultTenant.
+func tenantOf(w http.ResponseWriter, r *http.Request) (string, bool) {
+	tenant := r.URL.Query().Get("tenant")
+	if tenant == "" {
+		tenant = repo.DefaultTenant
+	}
+	if !reTenant.MatchString(tenant) {
+		http.Error(w, "bad tenant", http.StatusBadRequest)
+		return "", false
+	}
+	return tenant, true
+}
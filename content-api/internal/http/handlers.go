@@ -3,6 +3,7 @@ package http
 import (
 	"encoding/json"
 	"net/http"
+	"regexp"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"telegramBot/content-api/internal/repo"
@@ -10,6 +11,8 @@ import (
 
 type Server struct {
 	DB *pgxpool.Pool
+	// Tokens — токен → имя автора для изменяющих эндпоинтов (см. ParseTokens).
+	Tokens map[string]string
 }
 
 func (s *Server) Routes() http.Handler {
@@ -19,9 +22,13 @@ func (s *Server) Routes() http.Handler {
 		w.Write([]byte("ok"))
 	})
 	mux.HandleFunc("/content", s.getContent)
+	s.contentRoutes(mux)
+	s.menuRoutes(mux)
 	return mux
 }
 
+var reTenant = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
 func (s *Server) getContent(w http.ResponseWriter, r *http.Request) {
 	slug := r.URL.Query().Get("slug")
 	lang := r.URL.Query().Get("lang")
@@ -29,10 +36,14 @@ func (s *Server) getContent(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "missing slug", http.StatusBadRequest)
 		return
 	}
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
 	if lang != "kz" && lang != "ru" {
 		lang = "ru"
 	}
-	c, err := repo.GetBySlugLang(r.Context(), s.DB, slug, lang)
+	c, err := repo.GetBySlugLang(r.Context(), s.DB, tenant, slug, lang)
 	if err != nil {
 		http.Error(w, "not found", http.StatusNotFound)
 		return
@@ -0,0 +1,386 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"telegramBot/content-api/internal/menucheck"
+	"telegramBot/content-api/internal/repo"
+)
+
+var (
+	reCode = regexp.MustCompile(`^[a-z0-9_-]{1,56}$`) // попадает в callback data бота (≤ 64 байт), например pg:<code>:<page>
+	reKey  = regexp.MustCompile(`^[a-z0-9_.-]{1,100}$`)
+	reSlug = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+)
+
+const maxTranslation = 1000
+
+func (s *Server) menuRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /menu/nodes", s.auth(s.listNodes))
+	mux.HandleFunc("POST /menu/nodes", s.auth(s.createNode))
+	mux.HandleFunc("GET /menu/nodes/{code}", s.auth(s.getNode))
+	mux.HandleFunc("PATCH /menu/nodes/{code}", s.auth(s.updateNode))
+	mux.HandleFunc("DELETE /menu/nodes/{code}", s.auth(s.deleteNode))
+
+	mux.HandleFunc("GET /menu/nodes/{code}/buttons", s.auth(s.listButtons))
+	mux.HandleFunc("POST /menu/nodes/{code}/buttons", s.auth(s.createButton))
+	mux.HandleFunc("PUT /menu/nodes/{code}/buttons/order", s.auth(s.reorderButtons))
+	mux.HandleFunc("PATCH /menu/buttons/{id}", s.auth(s.updateButton))
+	mux.HandleFunc("DELETE /menu/buttons/{id}", s.auth(s.deleteButton))
+
+	mux.HandleFunc("GET /menu/validate", s.auth(s.validateMenu))
+
+	mux.HandleFunc("GET /menu/translations", s.auth(s.listTranslations))
+	mux.HandleFunc("PUT /menu/translations/{lang}/{key}", s.auth(s.putTranslation))
+	mux.HandleFunc("DELETE /menu/translations/{lang}/{key}", s.auth(s.deleteTranslation))
+}
+
+func (s *Server) listNodes(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	nodes, err := repo.ListNodes(r.Context(), s.DB, tenant)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nodes)
+}
+
+func (s *Server) getNode(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	n, err := repo.GetNode(r.Context(), s.DB, tenant, r.PathValue("code"))
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, n)
+}
+
+func (s *Server) createNode(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	n := repo.Node{Active: true, Columns: 1}
+	if !decode(w, r, &n) {
+		return
+	}
+	if msg := validateNode(n.Code, &n.TitleKey, n.Slug, n.Parent, &n.Columns, &n.PageSize); msg != "" {
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	n, err := repo.CreateNode(r.Context(), s.DB, tenant, n)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("menu: %s [%s] created node %s", Author(r.Context()), tenant, n.Code)
+	writeJSON(w, http.StatusCreated, n)
+}
+
+func (s *Server) updateNode(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	var p repo.NodePatch
+	if !decode(w, r, &p) {
+		return
+	}
+	code := r.PathValue("code")
+	if msg := validateNode(code, p.TitleKey, p.Slug, p.Parent, p.Columns, p.PageSize); msg != "" {
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	n, err := repo.UpdateNode(r.Context(), s.DB, tenant, code, p)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("menu: %s [%s] updated node %s", Author(r.Context()), tenant, code)
+	writeJSON(w, http.StatusOK, n)
+}
+
+func (s *Server) deleteNode(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	code := r.PathValue("code")
+	if err := repo.DeleteNode(r.Context(), s.DB, tenant, code); err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("menu: %s [%s] deleted node %s", Author(r.Context()), tenant, code)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listButtons(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	bs, err := repo.ListButtons(r.Context(), s.DB, tenant, r.PathValue("code"))
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, bs)
+}
+
+func (s *Server) createButton(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	b := repo.Button{Active: true}
+	if !decode(w, r, &b) {
+		return
+	}
+	if !reKey.MatchString(b.TextKey) {
+		http.Error(w, "bad text_key", http.StatusUnprocessableEntity)
+		return
+	}
+	if b.Next == nil || *b.Next == "" {
+		http.Error(w, "next is required", http.StatusUnprocessableEntity)
+		return
+	}
+	b, err := repo.CreateButton(r.Context(), s.DB, tenant, r.PathValue("code"), b)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("menu: %s [%s] created button %d on %s", Author(r.Context()), tenant, b.ID, b.Node)
+	writeJSON(w, http.StatusCreated, b)
+}
+
+func (s *Server) updateButton(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var p repo.ButtonPatch
+	if !decode(w, r, &p) {
+		return
+	}
+	if p.TextKey != nil && !reKey.MatchString(*p.TextKey) {
+		http.Error(w, "bad text_key", http.StatusUnprocessableEntity)
+		return
+	}
+	if p.Next != nil && *p.Next == "" {
+		http.Error(w, "next is required", http.StatusUnprocessableEntity)
+		return
+	}
+	b, err := repo.UpdateButton(r.Context(), s.DB, tenant, id, p)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("menu: %s [%s] updated button %d", Author(r.Context()), tenant, id)
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (s *Server) deleteButton(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := repo.DeleteButton(r.Context(), s.DB, tenant, id); err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("menu: %s [%s] deleted button %d", Author(r.Context()), tenant, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) reorderButtons(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		IDs []int64 `json:"ids"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	bs, err := repo.ReorderButtons(r.Context(), s.DB, tenant, r.PathValue("code"), req.IDs)
+	if errors.Is(err, repo.ErrBadRef) {
+		http.Error(w, "ids must list every button of the node exactly once", http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("menu: %s [%s] reordered buttons of %s", Author(r.Context()), tenant, r.PathValue("code"))
+	writeJSON(w, http.StatusOK, bs)
+}
+
+// validateMenu — то же, что cmd/menucheck: ok=false, если есть ошибки.
+// Корень меню задаётся в конфигурации бота (menu_root) и у каждого tenant
+// свой, поэтому root обязателен.
+func (s *Server) validateMenu(w http.ResponseWriter, r *http.Request) {
+	root := r.URL.Query().Get("root")
+	if !reCode.MatchString(root) {
+		http.Error(w, "root must be the code of the menu root node (menu_root of the bot)", http.StatusUnprocessableEntity)
+		return
+	}
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	m, err := menucheck.Load(r.Context(), s.DB, tenant)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	problems := menucheck.Check(m, root)
+	if problems == nil {
+		problems = []menucheck.Problem{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": !menucheck.HasErrors(problems), "problems": problems})
+}
+
+func (s *Server) listTranslations(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	ts, err := repo.ListTranslations(r.Context(), s.DB, tenant, r.URL.Query().Get("key"), r.URL.Query().Get("lang"))
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ts)
+}
+
+func (s *Server) putTranslation(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	t := repo.Translation{Lang: r.PathValue("lang"), Key: r.PathValue("key")}
+	var req struct {
+		Text string `json:"text"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	t.Text = req.Text
+	switch {
+	case t.Lang != "ru" && t.Lang != "kz":
+		http.Error(w, "lang must be ru or kz", http.StatusUnprocessableEntity)
+		return
+	case !reKey.MatchString(t.Key):
+		http.Error(w, "bad key", http.StatusUnprocessableEntity)
+		return
+	case t.Text == "" || utf8.RuneCountInString(t.Text) > maxTranslation:
+		http.Error(w, "text must be 1.."+strconv.Itoa(maxTranslation)+" characters", http.StatusUnprocessableEntity)
+		return
+	}
+	created, err := repo.PutTranslation(r.Context(), s.DB, tenant, t)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("menu: %s [%s] set translation %s/%s", Author(r.Context()), tenant, t.Lang, t.Key)
+	code := http.StatusOK
+	if created {
+		code = http.StatusCreated
+	}
+	writeJSON(w, code, t)
+}
+
+func (s *Server) deleteTranslation(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := tenantOf(w, r)
+	if !ok {
+		return
+	}
+	lang, key := r.PathValue("lang"), r.PathValue("key")
+	if err := repo.DeleteTranslation(r.Context(), s.DB, tenant, lang, key); err != nil {
+		repoError(w, err)
+		return
+	}
+	log.Printf("menu: %s [%s] deleted translation %s/%s", Author(r.Context()), tenant, lang, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateNode проверяет поля узла; nil — поле не меняется.
+func validateNode(code string, titleKey, slug, parent *string, columns, pageSize *int) string {
+	switch {
+	case !reCode.MatchString(code):
+		return "code must match " + reCode.String()
+	case titleKey != nil && !reKey.MatchString(*titleKey):
+		return "title_key must match " + reKey.String()
+	case slug != nil && *slug != "" && !reSlug.MatchString(*slug):
+		return "slug must match " + reSlug.String()
+	case parent != nil && *parent != "" && !reCode.MatchString(*parent):
+		return "parent must be a node code"
+	case columns != nil && (*columns < 1 || *columns > 4):
+		return "columns must be 1..4"
+	case pageSize != nil && (*pageSize < 0 || *pageSize > 50):
+		return "page_size must be 0..50 (0 — default)"
+	}
+	return ""
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "bad id", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		http.Error(w, "bad json: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func repoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrConflict):
+		http.Error(w, "already exists", http.StatusConflict)
+	case errors.Is(err, repo.ErrInUse):
+		http.Error(w, "node is referenced by buttons; retarget or delete them first", http.StatusConflict)
+	case errors.Is(err, repo.ErrBadRef):
+		http.Error(w, "referenced node does not exist or would become its own ancestor", http.StatusUnprocessableEntity)
+	default:
+		log.Printf("repo: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,262 @@
+package menucheck
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"telegramBot/content-api/internal/repo"
+)
+
+// Langs — языки, на которых должны быть все подписи меню.
+var Langs = []string{"ru", "kz"}
+
+const (
+	Error   = "error"
+	Warning = "warning"
+)
+
+// Problem — одна найденная ошибка в меню.
+type Problem struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Node     string `json:"node,omitempty"`
+	Button   int64  `json:"button,omitempty"`
+	Detail   string `json:"detail"`
+}
+
+// Menu — всё, что нужно для проверки, уже прочитанное из базы.
+type Menu struct {
+	Nodes        []repo.Node
+	Buttons      []repo.Button
+	Translations map[string]map[string]bool // lang → key
+	Content      map[string][]string        // slug → языки
+}
+
+func Load(ctx context.Context, db *pgxpool.Pool, tenant string) (Menu, error) {
+	var m Menu
+	var err error
+	if m.Nodes, err = repo.ListNodes(ctx, db, tenant); err != nil {
+		return m, err
+	}
+	if m.Buttons, err = repo.AllButtons(ctx, db, tenant); err != nil {
+		return m, err
+	}
+	ts, err := repo.ListTranslations(ctx, db, tenant, "", "")
+	if err != nil {
+		return m, err
+	}
+	m.Translations = map[string]map[string]bool{}
+	for _, t := range ts {
+		if m.Translations[t.Lang] == nil {
+			m.Translations[t.Lang] = map[string]bool{}
+		}
+		m.Translations[t.Lang][t.Key] = true
+	}
+	m.Content, err = repo.ContentLangs(ctx, db, tenant)
+	return m, err
+}
+
+// Check проверяет меню, начиная с узла root, и возвращает найденные проблемы:
+// тупиковые циклы, недостижимые узлы, кнопки без цели или на выключенный узел,
+// slug без контента и ключи без перевода. Error — бот покажет пользователю
+// сломанный экран, Warning — стоит поправить.
+func Check(m Menu, root string) []Problem {
+	var out []Problem
+	add := func(sev, kind, node string, button int64, format string, args ...any) {
+		out = append(out, Problem{Severity: sev, Kind: kind, Node: node, Button: button, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	nodes := map[string]repo.Node{}
+	for _, n := range m.Nodes {
+		nodes[n.Code] = n
+	}
+	edges := map[string][]string{} // только активные узлы и цели
+	hasButtons := map[string]bool{}
+
+	for _, b := range m.Buttons {
+		if !b.Active {
+			continue // бот её не показывает
+		}
+		from := nodes[b.Node]
+		switch {
+		case b.Foreign:
+			add(Error, "foreign_target", b.Node, b.ID, "кнопка %q ведёт на узел меню другого tenant", b.TextKey)
+			continue
+		case b.Next == nil:
+			add(Error, "missing_target", b.Node, b.ID, "кнопка %q никуда не ведёт (узел удалён)", b.TextKey)
+			continue
+		case !nodes[*b.Next].Active:
+			add(Error, "inactive_target", b.Node, b.ID, "кнопка %q ведёт на выключенный узел %q", b.TextKey, *b.Next)
+			continue
+		}
+		if from.Active {
+			edges[b.Node] = append(edges[b.Node], *b.Next)
+			hasButtons[b.Node] = true
+		}
+	}
+
+	r, ok := nodes[root]
+	switch {
+	case !ok:
+		add(Error, "missing_root", root, 0, "корневого узла %q нет", root)
+	case !r.Active:
+		add(Error, "missing_root", root, 0, "корневой узел %q выключен", root)
+	}
+
+	reach := map[string]bool{}
+	if r.Active {
+		stack := []string{root}
+		reach[root] = true
+		for len(stack) > 0 {
+			cur := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, next := range edges[cur] {
+				if !reach[next] {
+					reach[next] = true
+					stack = append(stack, next)
+				}
+			}
+		}
+	}
+
+	for _, n := range m.Nodes {
+		if !n.Active {
+			continue
+		}
+		if !reach[n.Code] && n.Code != root {
+			add(Warning, "orphan", n.Code, 0, "узел %q недостижим из %q", n.Code, root)
+		}
+		hasSlug := n.Slug != nil && *n.Slug != ""
+		if !hasSlug && !hasButtons[n.Code] {
+			add(Warning, "dead_end", n.Code, 0, "у узла %q нет ни контента, ни кнопок", n.Code)
+		}
+		if hasSlug {
+			langs := m.Content[*n.Slug]
+			switch {
+			case len(langs) == 0:
+				add(Error, "missing_content", n.Code, 0, "для slug %q нет контента", *n.Slug)
+			case !slices.Contains(langs, "ru"):
+				add(Error, "missing_content", n.Code, 0, "для slug %q нет контента на ru, а на него падают остальные языки", *n.Slug)
+			default:
+				for _, l := range Langs {
+					if !slices.Contains(langs, l) {
+						add(Warning, "missing_content", n.Code, 0, "для slug %q нет контента на %s, будет показан ru", *n.Slug, l)
+					}
+				}
+			}
+		}
+		for _, l := range Langs {
+			if !m.Translations[l][n.TitleKey] {
+				add(Error, "missing_translation", n.Code, 0, "нет перевода %s для title_key %q", l, n.TitleKey)
+			}
+		}
+	}
+	for _, b := range m.Buttons {
+		if !b.Active || !nodes[b.Node].Active {
+			continue
+		}
+		for _, l := range Langs {
+			if !m.Translations[l][b.TextKey] {
+				add(Error, "missing_translation", b.Node, b.ID, "нет перевода %s для text_key %q", l, b.TextKey)
+			}
+		}
+	}
+
+	for _, scc := range trapCycles(edges, nodes) {
+		add(Error, "cycle", scc[0], 0, "узлы %v ссылаются только друг на друга, без контента и выхода", scc)
+	}
+	return out
+}
+
+// trapCycles находит циклы (компоненты сильной связности), из которых
+// кнопками не выйти и в которых нет ни одного экрана с контентом.
+func trapCycles(edges map[string][]string, nodes map[string]repo.Node) [][]string {
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var sccs [][]string
+	next := 0
+
+	var visit func(v string)
+	visit = func(v string) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range edges[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var scc []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		sccs = append(sccs, scc)
+	}
+
+	codes := make([]string, 0, len(edges))
+	for c := range edges {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	for _, c := range codes {
+		if _, seen := index[c]; !seen {
+			visit(c)
+		}
+	}
+
+	var out [][]string
+	for _, scc := range sccs {
+		in := map[string]bool{}
+		for _, c := range scc {
+			in[c] = true
+		}
+		if len(scc) == 1 && !slices.Contains(edges[scc[0]], scc[0]) {
+			continue // не цикл
+		}
+		trap := true
+		for _, c := range scc {
+			if n := nodes[c]; n.Slug != nil && *n.Slug != "" {
+				trap = false
+			}
+			for _, w := range edges[c] {
+				if !in[w] {
+					trap = false
+				}
+			}
+		}
+		if trap {
+			sort.Strings(scc)
+			out = append(out, scc)
+		}
+	}
+	return out
+}
+
+// HasErrors — есть ли проблемы, с которыми меню публиковать нельзя.
+func HasErrors(ps []Problem) bool {
+	for _, p := range ps {
+		if p.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
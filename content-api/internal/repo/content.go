@@ -2,7 +2,9 @@ package repo
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -11,13 +13,161 @@ type Content struct {
 	Body  string `json:"body"`
 }
 
-func GetBySlugLang(ctx context.Context, db *pgxpool.Pool, slug, lang string) (Content, error) {
+const DefaultTenant = "default"
+
+// currentSQL выбирает текущую ревизию контента; строки, заведённые в content
+// мимо API и ещё без ревизий, отдаются как есть. Удалённый контент не отдаётся.
+const currentSQL = `select coalesce(r.title, c.title), coalesce(r.body, c.body)
+from content c left join content_revisions r on r.content_id = c.id and r.rev = c.current_rev
+where c.tenant=$1 and c.slug=$2 and c.lang=$3 and c.deleted_at is null`
+
+// GetBySlugLang отдаёт текущую ревизию, а если на lang её нет — русскую.
+func GetBySlugLang(ctx context.Context, db *pgxpool.Pool, tenant, slug, lang string) (Content, error) {
 	var c Content
-	err := db.QueryRow(ctx, `select title, body from content where slug=$1 and lang=$2`, slug, lang).
-		Scan(&c.Title, &c.Body)
+	err := db.QueryRow(ctx, currentSQL, tenant, slug, lang).Scan(&c.Title, &c.Body)
 	if err != nil && lang != "ru" {
-		err = db.QueryRow(ctx, `select title, body from content where slug=$1 and lang='ru'`, slug).
-			Scan(&c.Title, &c.Body)
+		err = db.QueryRow(ctx, currentSQL, tenant, slug, "ru").Scan(&c.Title, &c.Body)
 	}
 	return c, err
 }
+
+// ContentLangs — для каждого slug тенанта языки, на которых есть контент.
+func ContentLangs(ctx context.Context, db *pgxpool.Pool, tenant string) (map[string][]string, error) {
+	rows, err := db.Query(ctx, `select slug, lang from content where tenant=$1 and deleted_at is null order by slug, lang`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string][]string{}
+	for rows.Next() {
+		var slug, lang string
+		if err := rows.Scan(&slug, &lang); err != nil {
+			return nil, err
+		}
+		out[slug] = append(out[slug], lang)
+	}
+	return out, rows.Err()
+}
+
+// Item — строка контента целиком, для редактирования через API.
+type Item struct {
+	Tenant string `json:"tenant"`
+	Slug   string `json:"slug"`
+	Lang   string `json:"lang"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	// Rev — номер текущей ревизии (см. revisions.go).
+	Rev int `json:"rev"`
+}
+
+// ItemPatch — изменения контента; nil — поле не меняется.
+type ItemPatch struct {
+	Title *string `json:"title"`
+	Body  *string `json:"body"`
+}
+
+const itemCols = `tenant, slug, lang, title, body, current_rev`
+
+func scanItem(row pgx.Row) (Item, error) {
+	var it Item
+	err := row.Scan(&it.Tenant, &it.Slug, &it.Lang, &it.Title, &it.Body, &it.Rev)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return it, ErrNotFound
+	}
+	return it, err
+}
+
+func ListItems(ctx context.Context, db *pgxpool.Pool, tenant string) ([]Item, error) {
+	rows, err := db.Query(ctx, `select `+itemCols+` from content where tenant=$1 and deleted_at is null order by slug, lang`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Item{}
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+func GetItem(ctx context.Context, db *pgxpool.Pool, tenant, slug, lang string) (Item, error) {
+	return scanItem(db.QueryRow(ctx, `select `+itemCols+` from content
+where tenant=$1 and slug=$2 and lang=$3 and deleted_at is null`, tenant, slug, lang))
+}
+
+// CreateItem добавляет контент с первой ревизией от author; если slug уже
+// есть на этом языке — ErrConflict. Удалённый контент с тем же ключом
+// создаётся заново следующей ревизией, история сохраняется.
+func CreateItem(ctx context.Context, db *pgxpool.Pool, it Item, author string) (Item, error) {
+	err := pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		var id int64
+		err := tx.QueryRow(ctx, `insert into content (tenant, slug, lang, title, body, current_rev)
+values ($1, $2, $3, $4, $5, 0)
+on conflict (tenant, slug, lang) do update set title=excluded.title where content.deleted_at is not null
+returning id`, it.Tenant, it.Slug, it.Lang, it.Title, it.Body).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrConflict
+		}
+		if err != nil {
+			return mapErr(err)
+		}
+		it.Rev, err = addRevision(ctx, tx, id, it.Title, it.Body, author, 0)
+		return err
+	})
+	if err != nil {
+		return Item{}, err
+	}
+	return it, nil
+}
+
+// UpdateItem сохраняет изменения новой ревизией от author. Если текст не
+// изменился, ревизия не создаётся.
+func UpdateItem(ctx context.Context, db *pgxpool.Pool, tenant, slug, lang string, p ItemPatch, author string) (Item, error) {
+	var cur Item
+	err := pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		id, deleted, err := lockItem(ctx, tx, tenant, slug, lang)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			return ErrNotFound
+		}
+		if cur, err = scanItem(tx.QueryRow(ctx, `select `+itemCols+` from content where id=$1`, id)); err != nil {
+			return err
+		}
+		title, body := cur.Title, cur.Body
+		if p.Title != nil {
+			cur.Title = *p.Title
+		}
+		if p.Body != nil {
+			cur.Body = *p.Body
+		}
+		if cur.Title == title && cur.Body == body && cur.Rev > 0 {
+			return nil
+		}
+		cur.Rev, err = addRevision(ctx, tx, id, cur.Title, cur.Body, author, 0)
+		return err
+	})
+	if err != nil {
+		return Item{}, err
+	}
+	return cur, nil
+}
+
+// DeleteItem скрывает контент, не трогая ревизии: его историю можно
+// посмотреть, а откат (Rollback) возвращает его обратно.
+func DeleteItem(ctx context.Context, db *pgxpool.Pool, tenant, slug, lang string) error {
+	tag, err := db.Exec(ctx, `update content set deleted_at=now()
+where tenant=$1 and slug=$2 and lang=$3 and deleted_at is null`, tenant, slug, lang)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
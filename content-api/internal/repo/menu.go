@@ -0,0 +1,445 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	// ErrConflict — запись с таким ключом уже есть.
+	ErrConflict = errors.New("already exists")
+	// ErrBadRef — ссылка на несуществующий узел меню или на родителя,
+	// который сам лежит под узлом.
+	ErrBadRef = errors.New("referenced node does not exist")
+	// ErrInUse — на узел ещё ведут кнопки, удалить его нельзя.
+	ErrInUse = errors.New("node is referenced by buttons")
+)
+
+type Node struct {
+	ID       int64   `json:"id"`
+	Code     string  `json:"code"`
+	TitleKey string  `json:"title_key"`
+	Slug     *string `json:"slug"`
+	Parent   *string `json:"parent"`
+	Order    int     `json:"order"`
+	Active   bool    `json:"active"`
+	Columns  int     `json:"columns"`
+	PageSize int     `json:"page_size"`
+}
+
+// NodePatch — изменяемые поля узла; nil — не трогать. Пустые Slug и
+// Parent снимают значение.
+type NodePatch struct {
+	TitleKey *string `json:"title_key"`
+	Slug     *string `json:"slug"`
+	Parent   *string `json:"parent"`
+	Order    *int    `json:"order"`
+	Active   *bool   `json:"active"`
+	Columns  *int    `json:"columns"`
+	PageSize *int    `json:"page_size"`
+}
+
+type Button struct {
+	ID      int64   `json:"id"`
+	Node    string  `json:"node"`
+	TextKey string  `json:"text_key"`
+	Next    *string `json:"next"`
+	Order   int     `json:"order"`
+	// Active — показывать ли кнопку в боте; выключенная остаётся в базе.
+	Active bool `json:"active"`
+	// Foreign — кнопка ведёт на узел другого tenant (осталась с тех пор,
+	// когда меню было общим); Next тогда пуст, а бот кнопку не показывает.
+	Foreign bool `json:"foreign,omitempty"`
+}
+
+type ButtonPatch struct {
+	TextKey *string `json:"text_key"`
+	Next    *string `json:"next"`
+	Order   *int    `json:"order"`
+	Active  *bool   `json:"active"`
+}
+
+type Translation struct {
+	Lang string `json:"lang"`
+	Key  string `json:"key"`
+	Text string `json:"text"`
+}
+
+const nodeCols = `n.id, n.code, n.title_key, n.slug, p.code, n."order", n.active, n.columns, n.page_size`
+
+func scanNode(row pgx.Row) (Node, error) {
+	var n Node
+	err := row.Scan(&n.ID, &n.Code, &n.TitleKey, &n.Slug, &n.Parent, &n.Order, &n.Active, &n.Columns, &n.PageSize)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return n, ErrNotFound
+	}
+	return n, err
+}
+
+// Все функции меню работают в пределах tenant: у каждого бота своё меню.
+
+func ListNodes(ctx context.Context, db *pgxpool.Pool, tenant string) ([]Node, error) {
+	rows, err := db.Query(ctx, `select `+nodeCols+`
+from "узлы_меню" n left join "узлы_меню" p on p.id = n.parent_id
+where n.tenant = $1
+order by n."order", n.id`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Node{}
+	for rows.Next() {
+		n, err := scanNode(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+func GetNode(ctx context.Context, db *pgxpool.Pool, tenant, code string) (Node, error) {
+	return scanNode(db.QueryRow(ctx, `select `+nodeCols+`
+from "узлы_меню" n left join "узлы_меню" p on p.id = n.parent_id
+where n.tenant = $1 and n.code = $2`, tenant, code))
+}
+
+func CreateNode(ctx context.Context, db *pgxpool.Pool, tenant string, n Node) (Node, error) {
+	parentID, err := nodeID(ctx, db, tenant, n.Parent)
+	if err != nil {
+		return Node{}, err
+	}
+	_, err = db.Exec(ctx, `insert into "узлы_меню" (tenant, code, title_key, slug, parent_id, "order", active, columns, page_size)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`, tenant, n.Code, n.TitleKey, emptyToNull(n.Slug), parentID, n.Order, n.Active, n.Columns, n.PageSize)
+	if err != nil {
+		return Node{}, mapErr(err)
+	}
+	return GetNode(ctx, db, tenant, n.Code)
+}
+
+func UpdateNode(ctx context.Context, db *pgxpool.Pool, tenant, code string, p NodePatch) (Node, error) {
+	cur, err := GetNode(ctx, db, tenant, code)
+	if err != nil {
+		return Node{}, err
+	}
+	if p.TitleKey != nil {
+		cur.TitleKey = *p.TitleKey
+	}
+	if p.Slug != nil {
+		cur.Slug = emptyToNull(p.Slug)
+	}
+	if p.Parent != nil {
+		cur.Parent = emptyToNull(p.Parent)
+	}
+	if p.Order != nil {
+		cur.Order = *p.Order
+	}
+	if p.Active != nil {
+		cur.Active = *p.Active
+	}
+	if p.Columns != nil {
+		cur.Columns = *p.Columns
+	}
+	if p.PageSize != nil {
+		cur.PageSize = *p.PageSize
+	}
+	parentID, err := nodeID(ctx, db, tenant, cur.Parent)
+	if err != nil {
+		return Node{}, err
+	}
+	if parentID != nil {
+		// узел не может стать потомком самого себя: A→B→A
+		var cycle bool
+		err = db.QueryRow(ctx, `with recursive up(id, parent_id) as (
+  select id, parent_id from "узлы_меню" where id = $1
+  union
+  select n.id, n.parent_id from "узлы_меню" n join up on n.id = up.parent_id
+)
+select exists(select 1 from up where id = $2)`, *parentID, cur.ID).Scan(&cycle)
+		if err != nil {
+			return Node{}, err
+		}
+		if cycle {
+			return Node{}, ErrBadRef
+		}
+	}
+	_, err = db.Exec(ctx, `update "узлы_меню"
+set title_key=$2, slug=$3, parent_id=$4, "order"=$5, active=$6, columns=$7, page_size=$8 where id=$1`,
+		cur.ID, cur.TitleKey, cur.Slug, parentID, cur.Order, cur.Active, cur.Columns, cur.PageSize)
+	if err != nil {
+		return Node{}, mapErr(err)
+	}
+	return GetNode(ctx, db, tenant, code)
+}
+
+// DeleteNode удаляет узел вместе с его кнопками. Пока на узел ведут кнопки
+// других узлов — ErrInUse: сначала их нужно перенаправить или удалить.
+func DeleteNode(ctx context.Context, db *pgxpool.Pool, tenant, code string) error {
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		var id int64
+		err := tx.QueryRow(ctx, `select id from "узлы_меню" where tenant=$1 and code=$2 for update`, tenant, code).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var used bool
+		err = tx.QueryRow(ctx, `select exists(select 1 from "кнопки" where next_node_id=$1 and node_id<>$1)`, id).Scan(&used)
+		if err != nil {
+			return err
+		}
+		if used {
+			return ErrInUse
+		}
+		if _, err := tx.Exec(ctx, `delete from "узлы_меню" where id=$1`, id); err != nil {
+			var pg *pgconn.PgError
+			if errors.As(err, &pg) && pg.Code == "23503" {
+				// кнопку на узел добавили между проверкой и удалением
+				return ErrInUse
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+const buttonCols = `k.id, n.code, k.text_key, t.code, k."order", k.active, k.next_node_id is not null and t.id is null`
+
+func scanButton(row pgx.Row) (Button, error) {
+	var b Button
+	err := row.Scan(&b.ID, &b.Node, &b.TextKey, &b.Next, &b.Order, &b.Active, &b.Foreign)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return b, ErrNotFound
+	}
+	return b, err
+}
+
+func ListButtons(ctx context.Context, db *pgxpool.Pool, tenant, node string) ([]Button, error) {
+	if _, err := GetNode(ctx, db, tenant, node); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(ctx, `select `+buttonCols+`
+from "кнопки" k join "узлы_меню" n on n.id = k.node_id left join "узлы_меню" t on t.id = k.next_node_id and t.tenant = n.tenant
+where n.tenant = $1 and n.code = $2 order by k."order", k.id`, tenant, node)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Button{}
+	for rows.Next() {
+		b, err := scanButton(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// AllButtons — все кнопки всех узлов tenant, для проверки графа меню.
+func AllButtons(ctx context.Context, db *pgxpool.Pool, tenant string) ([]Button, error) {
+	rows, err := db.Query(ctx, `select `+buttonCols+`
+from "кнопки" k join "узлы_меню" n on n.id = k.node_id left join "узлы_меню" t on t.id = k.next_node_id and t.tenant = n.tenant
+where n.tenant = $1
+order by n.code, k."order", k.id`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Button{}
+	for rows.Next() {
+		b, err := scanButton(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+func GetButton(ctx context.Context, db *pgxpool.Pool, tenant string, id int64) (Button, error) {
+	return scanButton(db.QueryRow(ctx, `select `+buttonCols+`
+from "кнопки" k join "узлы_меню" n on n.id = k.node_id left join "узлы_меню" t on t.id = k.next_node_id and t.tenant = n.tenant
+where n.tenant = $1 and k.id = $2`, tenant, id))
+}
+
+// CreateButton добавляет кнопку на узел node. Next обязателен: кнопка,
+// которая никуда не ведёт, в боте не показывается.
+func CreateButton(ctx context.Context, db *pgxpool.Pool, tenant, node string, b Button) (Button, error) {
+	n, err := GetNode(ctx, db, tenant, node)
+	if err != nil {
+		return Button{}, err
+	}
+	if b.Next == nil {
+		return Button{}, ErrBadRef
+	}
+	nextID, err := nodeID(ctx, db, tenant, b.Next)
+	if err != nil {
+		return Button{}, err
+	}
+	var id int64
+	err = db.QueryRow(ctx, `insert into "кнопки" (node_id, text_key, next_node_id, "order", active)
+values ($1, $2, $3, $4, $5) returning id`, n.ID, b.TextKey, nextID, b.Order, b.Active).Scan(&id)
+	if err != nil {
+		return Button{}, mapErr(err)
+	}
+	return GetButton(ctx, db, tenant, id)
+}
+
+// UpdateButton меняет поля кнопки. Кнопку без цели (из старых данных)
+// можно править и не задавая next — например, сначала выключить.
+func UpdateButton(ctx context.Context, db *pgxpool.Pool, tenant string, id int64, p ButtonPatch) (Button, error) {
+	cur, err := GetButton(ctx, db, tenant, id)
+	if err != nil {
+		return Button{}, err
+	}
+	if p.TextKey != nil {
+		cur.TextKey = *p.TextKey
+	}
+	if p.Next != nil {
+		cur.Next = p.Next
+	}
+	if p.Order != nil {
+		cur.Order = *p.Order
+	}
+	if p.Active != nil {
+		cur.Active = *p.Active
+	}
+	nextID, err := nodeID(ctx, db, tenant, cur.Next)
+	if err != nil {
+		return Button{}, err
+	}
+	_, err = db.Exec(ctx, `update "кнопки" set text_key=$2, next_node_id=$3, "order"=$4, active=$5 where id=$1`,
+		id, cur.TextKey, nextID, cur.Order, cur.Active)
+	if err != nil {
+		return Button{}, mapErr(err)
+	}
+	return GetButton(ctx, db, tenant, id)
+}
+
+func DeleteButton(ctx context.Context, db *pgxpool.Pool, tenant string, id int64) error {
+	tag, err := db.Exec(ctx, `delete from "кнопки" k using "узлы_меню" n
+where n.id = k.node_id and n.tenant = $1 and k.id = $2`, tenant, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ReorderButtons расставляет кнопки узла в порядке ids (order = 1, 2, ...).
+// ids должен перечислять ровно все кнопки узла.
+func ReorderButtons(ctx context.Context, db *pgxpool.Pool, tenant, node string, ids []int64) ([]Button, error) {
+	cur, err := ListButtons(ctx, db, tenant, node)
+	if err != nil {
+		return nil, err
+	}
+	have := map[int64]bool{}
+	for _, b := range cur {
+		have[b.ID] = true
+	}
+	if len(ids) != len(cur) {
+		return nil, ErrBadRef
+	}
+	for _, id := range ids {
+		if !have[id] {
+			return nil, ErrBadRef
+		}
+		delete(have, id)
+	}
+	err = pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		for i, id := range ids {
+			if _, err := tx.Exec(ctx, `update "кнопки" set "order"=$2 where id=$1`, id, i+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ListButtons(ctx, db, tenant, node)
+}
+
+// ListTranslations фильтрует по key и lang, пустые — без фильтра.
+func ListTranslations(ctx context.Context, db *pgxpool.Pool, tenant, key, lang string) ([]Translation, error) {
+	rows, err := db.Query(ctx, `select lang, key, text from "переводы"
+where tenant = $1 and ($2 = '' or key = $2) and ($3 = '' or lang = $3) order by key, lang`, tenant, key, lang)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Translation{}
+	for rows.Next() {
+		var t Translation
+		if err := rows.Scan(&t.Lang, &t.Key, &t.Text); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// PutTranslation создаёт или заменяет перевод; created — его не было.
+func PutTranslation(ctx context.Context, db *pgxpool.Pool, tenant string, t Translation) (created bool, err error) {
+	err = db.QueryRow(ctx, `insert into "переводы" (tenant, lang, key, text) values ($1, $2, $3, $4)
+on conflict (tenant, lang, key) do update set text = excluded.text
+returning (xmax = 0)`, tenant, t.Lang, t.Key, t.Text).Scan(&created)
+	return created, err
+}
+
+func DeleteTranslation(ctx context.Context, db *pgxpool.Pool, tenant, lang, key string) error {
+	tag, err := db.Exec(ctx, `delete from "переводы" where tenant=$1 and lang=$2 and key=$3`, tenant, lang, key)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// nodeID переводит code узла tenant в id; nil code — nil id, неизвестный
+// (в том числе узел другого tenant) — ErrBadRef.
+func nodeID(ctx context.Context, db *pgxpool.Pool, tenant string, code *string) (*int64, error) {
+	if code == nil || *code == "" {
+		return nil, nil
+	}
+	var id int64
+	err := db.QueryRow(ctx, `select id from "узлы_меню" where tenant=$1 and code=$2`, tenant, *code).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrBadRef
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func emptyToNull(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
+func mapErr(err error) error {
+	var pg *pgconn.PgError
+	if errors.As(err, &pg) {
+		switch pg.Code {
+		case "23505": // unique_violation
+			return ErrConflict
+		case "23503": // foreign_key_violation: узел удалили между проверкой и записью
+			return ErrBadRef
+		}
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Revision — одна сохранённая версия контента. Ревизии не меняются и не
+// удаляются: откат создаёт новую ревизию с текстом старой.
+type Revision struct {
+	Rev       int       `json:"rev"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	// RestoredFrom — номер ревизии, к которой откатились; 0 — обычная правка.
+	RestoredFrom int  `json:"restored_from,omitempty"`
+	Current      bool `json:"current"`
+}
+
+const revisionCols = `r.rev, r.title, r.body, r.author, r.created_at, coalesce(r.restored_from, 0), r.rev = c.current_rev`
+
+func scanRevision(row pgx.Row) (Revision, error) {
+	var v Revision
+	err := row.Scan(&v.Rev, &v.Title, &v.Body, &v.Author, &v.CreatedAt, &v.RestoredFrom, &v.Current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return v, ErrNotFound
+	}
+	return v, err
+}
+
+// ListRevisions — история контента, новые ревизии первыми. История
+// удалённого контента тоже отдаётся, чтобы было к чему откатиться.
+func ListRevisions(ctx context.Context, db *pgxpool.Pool, tenant, slug, lang string) ([]Revision, error) {
+	var exists bool
+	err := db.QueryRow(ctx, `select exists(select 1 from content where tenant=$1 and slug=$2 and lang=$3)`,
+		tenant, slug, lang).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+	rows, err := db.Query(ctx, `select `+revisionCols+`
+from content c join content_revisions r on r.content_id = c.id
+where c.tenant=$1 and c.slug=$2 and c.lang=$3
+order by r.rev desc`, tenant, slug, lang)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Revision{}
+	for rows.Next() {
+		v, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// CurrentRev — номер текущей ревизии, в том числе удалённого контента;
+// 0 — ревизий ещё нет.
+func CurrentRev(ctx context.Context, db *pgxpool.Pool, tenant, slug, lang string) (int, error) {
+	var rev int
+	err := db.QueryRow(ctx, `select current_rev from content where tenant=$1 and slug=$2 and lang=$3`,
+		tenant, slug, lang).Scan(&rev)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return rev, err
+}
+
+func GetRevision(ctx context.Context, db *pgxpool.Pool, tenant, slug, lang string, rev int) (Revision, error) {
+	return scanRevision(db.QueryRow(ctx, `select `+revisionCols+`
+from content c join content_revisions r on r.content_id = c.id
+where c.tenant=$1 and c.slug=$2 and c.lang=$3 and r.rev=$4`, tenant, slug, lang, rev))
+}
+
+// Rollback делает текущим текст ревизии rev, записывая его новой ревизией
+// от author, чтобы и сам откат остался в истории. Удалённый контент при
+// этом восстанавливается.
+func Rollback(ctx context.Context, db *pgxpool.Pool, tenant, slug, lang string, rev int, author string) (Item, error) {
+	var it Item
+	err := pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		id, _, err := lockItem(ctx, tx, tenant, slug, lang)
+		if err != nil {
+			return err
+		}
+		var title, body string
+		err = tx.QueryRow(ctx, `select title, body from content_revisions where content_id=$1 and rev=$2`, id, rev).
+			Scan(&title, &body)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := addRevision(ctx, tx, id, title, body, author, rev); err != nil {
+			return err
+		}
+		it, err = scanItem(tx.QueryRow(ctx, `select `+itemCols+` from content where id=$1`, id))
+		return err
+	})
+	if err != nil {
+		return Item{}, err
+	}
+	return it, nil
+}
+
+// lockItem находит строку контента (и удалённую тоже) и блокирует её до
+// конца транзакции, чтобы параллельные правки не получили один номер ревизии.
+func lockItem(ctx context.Context, tx pgx.Tx, tenant, slug, lang string) (id int64, deleted bool, err error) {
+	err = tx.QueryRow(ctx, `select id, deleted_at is not null from content
+where tenant=$1 and slug=$2 and lang=$3 for update`, tenant, slug, lang).Scan(&id, &deleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, ErrNotFound
+	}
+	return id, deleted, err
+}
+
+// addRevision записывает следующую ревизию и делает её текущей; удалённый
+// контент снова становится виден. Строка content должна быть заблокирована
+// (lockItem) или только что создана. restoredFrom — номер ревизии при
+// откате, 0 — обычная правка.
+func addRevision(ctx context.Context, tx pgx.Tx, contentID int64, title, body, author string, restoredFrom int) (int, error) {
+	var rev int
+	err := tx.QueryRow(ctx, `insert into content_revisions (content_id, rev, title, body, author, restored_from)
+select $1, coalesce(max(rev), 0) + 1, $2, $3, $4, nullif($5, 0) from content_revisions where content_id = $1
+returning rev`, contentID, title, body, author, restoredFrom).Scan(&rev)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(ctx, `update content set title=$2, body=$3, current_rev=$4, deleted_at=null where id=$1`, contentID, title, body, rev)
+	return rev, err
+}
@@ -1,10 +1,18 @@
+-- Схема для новой базы. Существующие базы content-api обновляет при старте
+-- (content-api/internal/db/migrate.sql): новые таблицы и колонки добавляйте
+-- в оба файла.
 CREATE TABLE IF NOT EXISTS content (
     id SERIAL PRIMARY KEY,
+    tenant TEXT NOT NULL DEFAULT 'default',
     slug TEXT NOT NULL,
     lang TEXT NOT NULL,
     title TEXT NOT NULL,
     body TEXT NOT NULL,
-    UNIQUE(slug, lang)
+    -- номер ревизии в content_revisions, которую отдаёт GET /content
+    current_rev INT NOT NULL DEFAULT 0,
+    -- удалённый через API контент: не отдаётся, но ревизии остаются
+    deleted_at TIMESTAMPTZ,
+    UNIQUE(tenant, slug, lang)
     );
 INSERT INTO content (slug, lang, title, body) VALUES
                                                   ('programs','ru','Образовательные программы',
@@ -31,36 +39,70 @@ INSERT INTO content (slug, lang, title, body) VALUES
                                                   ('campus','ru','Студенческая жизнь в WKATU','Клубы и секции: IT, агротех, спорт, медиа. Регулярные мероприятия, волонтёрство, хакатоны. Спортзал и секции. Узнать актуальное — у студсовета.'),
                                                   ('campus','kz','WKATU студенттік өмірі','Клубтар мен секциялар: IT, агротех, спорт, медиа. Тұрақты іс-шаралар, волонтёрлік, хакатондар. Спортзал және секциялар. Актуалды — студенттер кеңесінде.');
 
+-- советы к разделу грантов для абитуриентов из региона профиля (hint-region-<регион>)
+INSERT INTO content (slug, lang, title, body) VALUES
+    ('hint-region-south','ru','Гранты «Серпін»','Для молодёжи из южных регионов есть отдельные гранты по программе «Серпін» — условия уточните в приёмной комиссии.'),
+    ('hint-region-south','kz','«Серпін» гранттары','Оңтүстік өңірлердің жастарына «Серпін» бағдарламасы бойынша жеке гранттар бөлінеді — шарттарын қабылдау комиссиясынан сұраңыз.')
+ON CONFLICT DO NOTHING;
+
+-- история правок контента: каждая правка и откат — новая ревизия
+CREATE TABLE IF NOT EXISTS content_revisions (
+    id            BIGSERIAL   PRIMARY KEY,
+    content_id    INT         NOT NULL REFERENCES content(id),
+    rev           INT         NOT NULL,
+    title         TEXT        NOT NULL,
+    body          TEXT        NOT NULL,
+    author        TEXT        NOT NULL,
+    restored_from INT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (content_id, rev)
+);
+
+-- засеянный выше контент — первая ревизия
+INSERT INTO content_revisions (content_id, rev, title, body, author)
+SELECT id, 1, title, body, 'init.sql' FROM content
+ON CONFLICT DO NOTHING;
+UPDATE content SET current_rev = 1 WHERE current_rev = 0;
+
 
 CREATE TABLE "узлы_меню" (
     id            BIGSERIAL PRIMARY KEY,
-    "code"        TEXT UNIQUE NOT NULL,
+    -- у каждого бота (tenant, как в content) своё меню
+    "tenant"      TEXT NOT NULL DEFAULT 'default',
+    "code"        TEXT NOT NULL,
     "title_key"   TEXT NOT NULL,
     "slug"        TEXT,
     "parent_id"   BIGINT REFERENCES "узлы_меню"(id) ON DELETE SET NULL,
     "order"       INT NOT NULL DEFAULT 0,
-    "active"      BOOLEAN NOT NULL DEFAULT TRUE
+    "active"      BOOLEAN NOT NULL DEFAULT TRUE,
+    -- раскладка кнопок узла в боте: сколько в ряд и сколько на странице (0 — по умолчанию)
+    "columns"     INT NOT NULL DEFAULT 1 CHECK ("columns" BETWEEN 1 AND 4),
+    "page_size"   INT NOT NULL DEFAULT 0 CHECK ("page_size" BETWEEN 0 AND 50),
+    UNIQUE ("tenant", "code")
 );
 
 CREATE TABLE "кнопки" (
     id BIGSERIAL PRIMARY KEY,
     node_id BIGINT NOT NULL REFERENCES узлы_меню(id) ON DELETE CASCADE,
     "text_key" TEXT NOT NULL,
-    "next_node_id"  BIGINT REFERENCES "узлы_меню"(id) ON DELETE SET NULL,
-    "order" INT NOT NULL DEFAULT 0
+    -- узел, на который ведут кнопки, удалить нельзя (DeleteNode отвечает 409)
+    "next_node_id"  BIGINT REFERENCES "узлы_меню"(id),
+    "order" INT NOT NULL DEFAULT 0,
+    "active" BOOLEAN NOT NULL DEFAULT TRUE
 );
 
 CREATE TABLE "переводы" (
-    id      BIGSERIAL PRIMARY KEY,
-    "lang"  TEXT NOT NULL,
-    "key"   TEXT NOT NULL,
-    "text"  TEXT NOT NULL,
-    UNIQUE("lang","key")
+    id        BIGSERIAL PRIMARY KEY,
+    "tenant"  TEXT NOT NULL DEFAULT 'default',
+    "lang"    TEXT NOT NULL,
+    "key"     TEXT NOT NULL,
+    "text"    TEXT NOT NULL,
+    UNIQUE("tenant","lang","key")
 );
 
 CREATE INDEX ON "узлы_меню"("parent_id");
 CREATE INDEX ON "кнопки"("node_id");
-CREATE INDEX ON "переводы"("key","lang");
+CREATE INDEX ON "переводы"("tenant","key","lang");
 
 -- 02_seed.sql
 INSERT INTO "узлы_меню"("code","title_key","order")
@@ -115,3 +157,65 @@ INSERT INTO "переводы"("lang","key","text") VALUES
 
                                                 ('ru','btn.dorm','🏠 Общежитие'),
                                                 ('kz','btn.dorm','🏠 Жатақхана');
+
+-- сессии чатов бота (bot/internal/session), data — версионированный JSON
+CREATE TABLE IF NOT EXISTS bot_sessions (
+    bot        TEXT        NOT NULL,
+    chat_id    BIGINT      NOT NULL,
+    data       JSONB       NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (bot, chat_id)
+);
+CREATE INDEX IF NOT EXISTS bot_sessions_updated_idx ON bot_sessions (bot, updated_at);
+
+-- профили абитуриентов (bot/internal/profile): по пользователю, без срока жизни
+CREATE TABLE IF NOT EXISTS bot_profiles (
+    bot        TEXT        NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    data       JSONB       NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (bot, user_id)
+);
+
+-- аудит выгрузки (/mydata) и удаления (/forget) данных пользователей бота
+CREATE TABLE IF NOT EXISTS privacy_audit (
+    id         BIGSERIAL   PRIMARY KEY,
+    bot        TEXT        NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    action     TEXT        NOT NULL,
+    holders    TEXT[]      NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS privacy_audit_user_idx ON privacy_audit (user_id);
+
+-- события бота для аналитики: откуда пришёл пользователь (deep-link /start) и т. п.
+CREATE TABLE IF NOT EXISTS bot_events (
+    id         BIGSERIAL   PRIMARY KEY,
+    bot        TEXT        NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    kind       TEXT        NOT NULL,
+    source     TEXT        NOT NULL DEFAULT '',
+    payload    TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS bot_events_user_idx ON bot_events (bot, user_id);
+CREATE INDEX IF NOT EXISTS bot_events_source_idx ON bot_events (kind, source, created_at);
+
+-- синонимы разделов для бота (bot/internal/resolver): как пользователи пишут руками
+CREATE TABLE IF NOT EXISTS synonyms (
+    id     BIGSERIAL PRIMARY KEY,
+    tenant TEXT NOT NULL DEFAULT 'default',
+    lang   TEXT NOT NULL CHECK (lang IN ('ru','kz')),
+    phrase TEXT NOT NULL,
+    slug   TEXT NOT NULL,
+    UNIQUE (tenant, lang, phrase)
+);
+
+INSERT INTO synonyms (lang, phrase, slug) VALUES
+    ('ru','поступление на бюджет','grants'),
+    ('ru','скидки','grants'),
+    ('ru','где жить','dorm'),
+    ('ru','заселение','dorm'),
+    ('kz','қайда тұрамын','dorm'),
+    ('ru','что сдавать','documents'),
+    ('kz','не тапсыру керек','documents');